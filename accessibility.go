@@ -0,0 +1,186 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	flashTime           = 18
+	flashAlpha          = 0.7
+	reducedFlashAlpha   = 0.12
+	outlineWidth        = 1
+	highContrastBGLevel = 0.35 // The background is dimmed to this brightness.
+)
+
+type paletteName string
+
+const (
+	paletteStandard   paletteName = "standard"
+	paletteRedGreen   paletteName = "redGreen"
+	paletteBlueYellow paletteName = "blueYellow"
+)
+
+// paletteNames lists the palettes in the order the settings menu cycles
+// through them.
+var paletteNames = []paletteName{paletteStandard, paletteRedGreen, paletteBlueYellow}
+
+// palette holds the colors that carry meaning, such as a full KIEE gauge.
+// The color-blind palettes keep those pairs apart in brightness as well as hue.
+type palette struct {
+	kieeBackground color.RGBA
+	kieeFill       color.RGBA
+	kieeFull       color.RGBA
+	bossBackground color.RGBA
+	bossFill       color.RGBA
+	powerUpBorder  color.RGBA
+	powerUpInside  color.RGBA
+	powerUpText    color.RGBA
+	powerText      color.RGBA
+}
+
+var palettes = map[paletteName]palette{
+	paletteStandard: {
+		kieeBackground: color.RGBA{R: 45, G: 45, B: 60, A: 255},
+		kieeFill:       color.RGBA{R: 55, G: 190, B: 255, A: 255},
+		kieeFull:       color.RGBA{R: 255, G: 215, B: 55, A: 255},
+		bossBackground: color.RGBA{R: 60, G: 20, B: 20, A: 255},
+		bossFill:       color.RGBA{R: 230, G: 45, B: 35, A: 255},
+		powerUpBorder:  color.RGBA{R: 255, G: 120, B: 35, A: 255},
+		powerUpInside:  color.RGBA{R: 255, G: 225, B: 65, A: 255},
+		powerUpText:    color.RGBA{R: 120, G: 35, B: 15, A: 255},
+		powerText:      color.RGBA{R: 255, G: 225, B: 70, A: 255},
+	},
+	// Blue and orange from the Okabe-Ito set stay distinct for protanopia and
+	// deuteranopia.
+	paletteRedGreen: {
+		kieeBackground: color.RGBA{R: 45, G: 45, B: 60, A: 255},
+		kieeFill:       color.RGBA{R: 0, G: 114, B: 178, A: 255},
+		kieeFull:       color.RGBA{R: 240, G: 228, B: 66, A: 255},
+		bossBackground: color.RGBA{R: 30, G: 30, B: 70, A: 255},
+		bossFill:       color.RGBA{R: 230, G: 159, B: 0, A: 255},
+		powerUpBorder:  color.RGBA{R: 0, G: 114, B: 178, A: 255},
+		powerUpInside:  color.RGBA{R: 240, G: 228, B: 66, A: 255},
+		powerUpText:    color.RGBA{A: 255},
+		powerText:      color.RGBA{R: 240, G: 228, B: 66, A: 255},
+	},
+	// Tritanopia confuses blue with green and yellow with violet, so the
+	// gauges use gray against vermilion and pink instead.
+	paletteBlueYellow: {
+		kieeBackground: color.RGBA{R: 45, G: 45, B: 45, A: 255},
+		kieeFill:       color.RGBA{R: 190, G: 190, B: 200, A: 255},
+		kieeFull:       color.RGBA{R: 230, G: 60, B: 110, A: 255},
+		bossBackground: color.RGBA{R: 60, G: 20, B: 20, A: 255},
+		bossFill:       color.RGBA{R: 213, G: 94, B: 0, A: 255},
+		powerUpBorder:  color.RGBA{R: 213, G: 94, B: 0, A: 255},
+		powerUpInside:  color.RGBA{R: 240, G: 240, B: 240, A: 255},
+		powerUpText:    color.RGBA{A: 255},
+		powerText:      color.RGBA{R: 255, G: 140, B: 170, A: 255},
+	},
+}
+
+// accessibilitySettings are off by default, matching the original look.
+type accessibilitySettings struct {
+	Palette      paletteName `json:"palette"`
+	HighContrast bool        `json:"highContrast"`
+	ReducedFlash bool        `json:"reducedFlash"`
+	Captions     bool        `json:"captions"`
+}
+
+func defaultAccessibilitySettings() accessibilitySettings {
+	return accessibilitySettings{Palette: paletteStandard}
+}
+
+func (settings accessibilitySettings) clamped() accessibilitySettings {
+	if _, ok := palettes[settings.Palette]; !ok {
+		settings.Palette = paletteStandard
+	}
+	return settings
+}
+
+func (g *Game) palette() palette {
+	if colors, ok := palettes[g.settings.Accessibility.Palette]; ok {
+		return colors
+	}
+	return palettes[paletteStandard]
+}
+
+func (g *Game) cyclePalette(delta int) {
+	current := 0
+	for index, name := range paletteNames {
+		if name == g.settings.Accessibility.Palette {
+			current = index
+		}
+	}
+	g.settings.Accessibility.Palette = paletteNames[(current+delta+len(paletteNames))%len(paletteNames)]
+	g.saveSettings()
+}
+
+func (g *Game) accessibilityMenuItems() []menuItem {
+	return []menuItem{
+		{
+			label:  "settings.palette",
+			value:  func() string { return g.message("palette." + string(g.settings.Accessibility.clamped().Palette)) },
+			adjust: g.cyclePalette,
+		},
+		g.toggleItem("settings.highContrast", &g.settings.Accessibility.HighContrast),
+		g.toggleItem("settings.reducedFlash", &g.settings.Accessibility.ReducedFlash),
+		g.toggleItem("settings.captions", &g.settings.Accessibility.Captions),
+	}
+}
+
+// flash briefly whitens the screen for big moments like the KIEE special.
+// Reduced-flash mode keeps it as a faint tint.
+func (g *Game) flash() {
+	g.flashTicks = flashTime
+}
+
+func (g *Game) updateFlash() {
+	g.flashTicks = max(0, g.flashTicks-1)
+}
+
+func (g *Game) drawFlash(screen *ebiten.Image) {
+	if g.flashTicks <= 0 {
+		return
+	}
+	peak := flashAlpha
+	if g.settings.Accessibility.ReducedFlash {
+		peak = reducedFlashAlpha
+	}
+	alpha := peak * float64(g.flashTicks) / flashTime
+	ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.NRGBA{R: 255, G: 255, B: 255, A: uint8(alpha * 255)})
+}
+
+func (g *Game) drawBackground(screen *ebiten.Image) {
+	options := &ebiten.DrawImageOptions{}
+	if g.settings.Accessibility.HighContrast {
+		options.ColorScale.Scale(highContrastBGLevel, highContrastBGLevel, highContrastBGLevel, 1)
+	}
+	screen.DrawImage(g.backgroundImg, options)
+}
+
+// drawSprite draws an enemy or projectile, outlined in high-contrast mode so
+// it stands out from the background.
+func (g *Game) drawSprite(screen, img *ebiten.Image, geoM ebiten.GeoM) {
+	if g.settings.Accessibility.HighContrast {
+		var silhouette colorm.ColorM
+		silhouette.Scale(0, 0, 0, 1)
+		silhouette.Translate(1, 1, 1, 0)
+		for _, offset := range [][2]float64{{-outlineWidth, 0}, {outlineWidth, 0}, {0, -outlineWidth}, {0, outlineWidth}} {
+			options := &colorm.DrawImageOptions{GeoM: geoM}
+			options.GeoM.Translate(offset[0], offset[1])
+			colorm.DrawImage(screen, img, silhouette, options)
+		}
+	}
+	screen.DrawImage(img, &ebiten.DrawImageOptions{GeoM: geoM})
+}
+
+func (g *Game) drawSpriteAt(screen, img *ebiten.Image, position sim.Point) {
+	var geoM ebiten.GeoM
+	geoM.Translate(position.X, position.Y)
+	g.drawSprite(screen, img, geoM)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestCaptionsOnlyShowWhenEnabled(t *testing.T) {
+	g := &Game{}
+	g.showCaption("caption.bossAttack")
+	if len(g.captions) != 0 {
+		t.Fatalf("captions = %v, want none while captions are off", g.captions)
+	}
+
+	g.settings.Accessibility.Captions = true
+	g.showCaption("caption.bossAttack")
+	g.updateCaptions()
+	g.showCaption("caption.bossAttack")
+	if len(g.captions) != 1 || g.captions[0].ticks != captionTime {
+		t.Fatalf("captions = %+v, want one refreshed boss attack caption", g.captions)
+	}
+	for _, key := range []string{"caption.special", "caption.ebiHit", "caption.powerUp"} {
+		g.showCaption(key)
+	}
+	if len(g.captions) != maxCaptions || g.captions[0].key != "caption.special" {
+		t.Fatalf("captions = %+v, want the oldest dropped", g.captions)
+	}
+	for range captionTime {
+		g.updateCaptions()
+	}
+	if len(g.captions) != 0 {
+		t.Fatalf("captions = %+v, want them expired", g.captions)
+	}
+}
+
+func TestPowerUpShowsCaption(t *testing.T) {
+	g := &Game{}
+	g.settings.Accessibility.Captions = true
+	g.effectEvent(sim.PowerUpCollected{})
+	if len(g.captions) != 1 || g.captions[0].key != "caption.powerUp" {
+		t.Fatalf("captions = %+v, want a power-up caption", g.captions)
+	}
+}
+
+func TestPalettesDistinguishFullKIEEGauge(t *testing.T) {
+	for _, name := range paletteNames {
+		colors := palettes[name]
+		if colors.kieeFill == colors.kieeFull {
+			t.Fatalf("%s palette uses the same color for charging and full gauges", name)
+		}
+		luminance := func(r, g, b uint8) float64 { return 0.2126*float64(r) + 0.7152*float64(g) + 0.0722*float64(b) }
+		fill := luminance(colors.kieeFill.R, colors.kieeFill.G, colors.kieeFill.B)
+		full := luminance(colors.kieeFull.R, colors.kieeFull.G, colors.kieeFull.B)
+		if name != paletteStandard && (full-fill < 40 && fill-full < 40) {
+			t.Errorf("%s palette gauge colors differ by %.0f in brightness, want a clear difference", name, full-fill)
+		}
+	}
+}
+
+func TestDecodeSettingsResetsUnknownPalette(t *testing.T) {
+	decoded, err := decodeSettings([]byte(`{"accessibility":{"palette":"sepia","captions":true}}`))
+	if err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if decoded.Accessibility.Palette != paletteStandard || !decoded.Accessibility.Captions {
+		t.Fatalf("accessibility = %+v, want the standard palette with captions kept", decoded.Accessibility)
+	}
+}
+
+func TestSubmenuReturnsToParent(t *testing.T) {
+	g := &Game{}
+	g.openSettings()
+	top := g.menu
+	for _, item := range top.items {
+		if item.label == "settings.accessibility" {
+			item.adjust(1)
+		}
+	}
+	if g.menu == top || g.menu.parent != top {
+		t.Fatal("accessibility item did not open a submenu")
+	}
+	g.closeMenu()
+	if g.menu != top || g.state != stateSettings {
+		t.Fatal("closing the submenu did not return to the settings page")
+	}
+	g.closeMenu()
+	if g.menu != nil || g.state != stateTitle {
+		t.Fatal("closing the settings page did not return to the title")
+	}
+}
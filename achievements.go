@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	achievementsSaveName  = "achievements"
+	achievementCombo      = 50
+	achievementNoKIEEWave = 10
+	achievementPowerUps   = 3
+	toastTime             = 3 * 60
+	toastTop              = 130 // Keeps toasts below the wave banner.
+	toastFadeTime         = 20
+)
+
+type achievement string
+
+const (
+	achievementFirstBoss   achievement = "firstBoss"
+	achievementBigCombo    achievement = "bigCombo"
+	achievementNoKIEE      achievement = "noKIEE"
+	achievementSparedEbis  achievement = "sparedEbis"
+	achievementPowerHungry achievement = "powerHungry"
+)
+
+// achievements lists the achievements in the order the achievements screen
+// shows them.
+var achievements = []achievement{achievementFirstBoss, achievementBigCombo, achievementNoKIEE, achievementSparedEbis, achievementPowerHungry}
+
+// achievementRecord maps each unlocked achievement to the date it was
+// unlocked.
+type achievementRecord map[achievement]string
+
+type achievementStore interface {
+	Load() (achievementRecord, error)
+	Save(record achievementRecord) error
+}
+
+type savedAchievementStore struct{}
+
+func newAchievementStore() achievementStore {
+	return savedAchievementStore{}
+}
+
+func (savedAchievementStore) Load() (achievementRecord, error) {
+	data, err := readSaveData(achievementsSaveName)
+	if err != nil || data == nil {
+		return achievementRecord{}, err
+	}
+	record := achievementRecord{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return achievementRecord{}, fmt.Errorf("parse achievements: %w", err)
+	}
+	return record, nil
+}
+
+func (savedAchievementStore) Save(record achievementRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return writeSaveData(achievementsSaveName, data)
+}
+
+// achievementProgress follows the current run and wave for the achievements
+// that depend on more than one event.
+type achievementProgress struct {
+	usedKIEE     bool
+	ebisSeen     int
+	ebisShot     int
+	wavePowerUps int
+}
+
+// toast is an on-screen notice of a newly unlocked achievement.
+type toast struct {
+	message string
+	ticks   int
+}
+
+func (g *Game) loadAchievements() {
+	g.unlocked = achievementRecord{}
+	if g.achievementStore == nil {
+		return
+	}
+	record, err := g.achievementStore.Load()
+	if err != nil {
+		log.Printf("load achievements: %v", err)
+	}
+	g.unlocked = record
+}
+
+// achievementsCount reports whether this run can unlock achievements.
+// Practice starts anywhere, assists make the goals easier and debug mode is
+// invincible and can jump to the bosses.
+func (g *Game) achievementsCount() bool {
+	return !g.assisted && !g.debug && g.mode != modePractice
+}
+
+// unlock records an achievement the first time it is earned and shows a toast.
+func (g *Game) unlock(id achievement) {
+	if !g.achievementsCount() || g.unlocked[id] != "" {
+		return
+	}
+	if g.unlocked == nil {
+		g.unlocked = achievementRecord{}
+	}
+	g.unlocked[id] = time.Now().Format(time.DateOnly)
+	log.Printf("achievement unlocked: %s (wave=%d score=%d)", id, g.world.Wave, g.world.Score)
+	if g.achievementStore != nil {
+		if err := g.achievementStore.Save(g.unlocked); err != nil {
+			log.Printf("save achievements: %v", err)
+		}
+	}
+	g.toasts = append(g.toasts, toast{message: g.message("achievement.unlocked", g.message("achievement."+string(id))), ticks: toastTime})
+	g.playSound(g.hitSound)
+}
+
+// achievementEvent follows the gameplay events the achievements depend on.
+func (g *Game) achievementEvent(event sim.Event) {
+	switch event := event.(type) {
+	case sim.UFODestroyed:
+		g.achievementHit(g.world.Pilots[event.Shooter].Combo)
+	case sim.BossDamaged:
+		g.achievementHit(g.world.Pilots[event.Shooter].Combo)
+	case sim.EbiSpawned:
+		g.achievementEbiSpawned()
+	case sim.EbiHit:
+		g.achievementEbiShot()
+	case sim.BossDefeated:
+		g.achievementBossDefeated()
+	case sim.WaveCleared:
+		g.achievementWaveCleared(event.Wave)
+	case sim.WaveStarted:
+		g.achievementWaveStarted()
+	case sim.PowerUpCollected:
+		g.achievementPowerUpCollected()
+	case sim.SpecialUsed:
+		g.achievementKIEEUsed()
+	}
+}
+
+func (g *Game) achievementHit(combo int) {
+	if combo >= achievementCombo {
+		g.unlock(achievementBigCombo)
+	}
+}
+
+func (g *Game) achievementBossDefeated() {
+	g.unlock(achievementFirstBoss)
+}
+
+func (g *Game) achievementKIEEUsed() {
+	g.progress.usedKIEE = true
+}
+
+func (g *Game) achievementEbiSpawned() {
+	g.progress.ebisSeen++
+}
+
+func (g *Game) achievementEbiShot() {
+	g.progress.ebisShot++
+}
+
+func (g *Game) achievementPowerUpCollected() {
+	g.progress.wavePowerUps++
+	if g.progress.wavePowerUps >= achievementPowerUps {
+		g.unlock(achievementPowerHungry)
+	}
+}
+
+// achievementWaveCleared checks the wave goals before the next wave starts.
+// A wave only counts for sparing the ebis if any ebis showed up in it.
+func (g *Game) achievementWaveCleared(wave int) {
+	if wave >= achievementNoKIEEWave && !g.progress.usedKIEE {
+		g.unlock(achievementNoKIEE)
+	}
+	if g.progress.ebisSeen > 0 && g.progress.ebisShot == 0 {
+		g.unlock(achievementSparedEbis)
+	}
+}
+
+// achievementWaveStarted resets the per-wave counters.
+func (g *Game) achievementWaveStarted() {
+	g.progress.ebisSeen = 0
+	g.progress.ebisShot = 0
+	g.progress.wavePowerUps = 0
+}
+
+func (g *Game) updateToasts() {
+	for index := len(g.toasts) - 1; index >= 0; index-- {
+		g.toasts[index].ticks--
+		if g.toasts[index].ticks <= 0 {
+			g.toasts = removeAt(g.toasts, index)
+		}
+	}
+}
+
+// drawToasts stacks unlock notices at the top of the screen, oldest first.
+func (g *Game) drawToasts(screen *ebiten.Image) {
+	stack := g.newHUDStack(screen, anchorTopCenter)
+	stack.used = toastTop * stack.scale
+	face := stack.face(fontMedium)
+	padding := captionPadding * stack.scale
+	for _, item := range g.toasts {
+		width, height := measureText(face, item.message)
+		x, y := stack.row(width+2*padding, height+2*padding)
+		alpha := min(1, float64(item.ticks)/toastFadeTime)
+		ebitenutil.DrawRect(screen, x, y, width+2*padding, height+2*padding, color.NRGBA{R: 60, G: 45, B: 10, A: uint8(210 * alpha)})
+		drawTextAt(screen, item.message, face, x+padding, y+padding, color.NRGBA{R: 255, G: 220, B: 70, A: uint8(255 * alpha)})
+	}
+}
+
+// openAchievements shows every achievement with its unlock date.
+func (g *Game) openAchievements() {
+	items := make([]menuItem, 0, len(achievements))
+	for _, id := range achievements {
+		items = append(items, menuItem{
+			label: "achievement." + string(id),
+			value: func() string {
+				if date := g.unlocked[id]; date != "" {
+					return date
+				}
+				return g.message("achievements.locked")
+			},
+			adjust: func(int) {},
+		})
+	}
+	g.menu = &menu{title: "achievements.title", items: items}
+	g.state = stateSettings
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+type fakeAchievementStore struct {
+	record achievementRecord
+	saves  int
+}
+
+func (store *fakeAchievementStore) Load() (achievementRecord, error) {
+	return store.record, nil
+}
+
+func (store *fakeAchievementStore) Save(record achievementRecord) error {
+	store.record = record
+	store.saves++
+	return nil
+}
+
+func TestAchievementUnlocksOnceAndIsSaved(t *testing.T) {
+	store := &fakeAchievementStore{record: achievementRecord{}}
+	g := &Game{achievementStore: store, world: &sim.World{}}
+	g.loadAchievements()
+	g.achievementBossDefeated()
+	g.achievementBossDefeated()
+	if store.saves != 1 || store.record[achievementFirstBoss] == "" || len(g.toasts) != 1 {
+		t.Fatalf("saves = %d record = %v toasts = %d, want one unlock with one toast", store.saves, store.record, len(g.toasts))
+	}
+}
+
+func TestPracticeAssistedAndDebugRunsDoNotUnlock(t *testing.T) {
+	for _, g := range []*Game{{mode: modePractice}, {assisted: true}, {debug: true}} {
+		g.achievementHit(achievementCombo)
+		if len(g.unlocked) != 0 {
+			t.Fatalf("mode %q assisted=%v debug=%v unlocked %v", g.mode, g.assisted, g.debug, g.unlocked)
+		}
+	}
+}
+
+func TestWaveAchievements(t *testing.T) {
+	g := &Game{world: &sim.World{}}
+	g.achievementWaveCleared(achievementNoKIEEWave - 1)
+	if len(g.unlocked) != 0 {
+		t.Fatalf("unlocked %v after a wave without ebis before wave %d", g.unlocked, achievementNoKIEEWave)
+	}
+
+	g.achievementEbiSpawned()
+	g.achievementEbiShot()
+	g.achievementKIEEUsed()
+	g.achievementWaveCleared(achievementNoKIEEWave)
+	if len(g.unlocked) != 0 {
+		t.Fatalf("unlocked %v after shooting an ebi and using KIEE", g.unlocked)
+	}
+
+	g.achievementWaveStarted()
+	g.achievementEbiSpawned()
+	g.achievementWaveCleared(achievementNoKIEEWave)
+	if g.unlocked[achievementSparedEbis] == "" || g.unlocked[achievementNoKIEE] != "" {
+		t.Fatalf("unlocked %v, want only the spared ebis", g.unlocked)
+	}
+}
+
+func TestPowerUpsCountPerWave(t *testing.T) {
+	g := &Game{world: &sim.World{}}
+	g.achievementPowerUpCollected()
+	g.achievementPowerUpCollected()
+	g.achievementWaveStarted()
+	g.achievementPowerUpCollected()
+	if g.unlocked[achievementPowerHungry] != "" {
+		t.Fatal("power-ups from an earlier wave counted")
+	}
+	g.achievementPowerUpCollected()
+	g.achievementPowerUpCollected()
+	if g.unlocked[achievementPowerHungry] == "" {
+		t.Fatalf("%d power-ups in one wave did not unlock", achievementPowerUps)
+	}
+}
@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 )
 
@@ -13,6 +14,10 @@ func openAsset(path string) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s: %w", path, response.Status, fs.ErrNotExist)
+	}
 	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
 		response.Body.Close()
 		return nil, fmt.Errorf("GET %s: %s", path, response.Status)
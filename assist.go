@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	minGameSpeed     = 50
+	maxGameSpeed     = 100
+	gameSpeedStep    = 10
+	hitboxAssistStep = 4
+	maxHitboxAssist  = 8
+)
+
+// assistSettings make the game easier. Any run that starts with an assist
+// enabled is marked as assisted and cannot set a high score.
+type assistSettings struct {
+	GameSpeed     int  `json:"gameSpeed"`     // Percent of the normal 60 TPS; zero means full speed.
+	AutoFire      bool `json:"autoFire"`      // Fire as if Space were held.
+	HitboxPadding int  `json:"hitboxPadding"` // Extra pixels trimmed from the player's hitbox.
+	Invincible    bool `json:"invincible"`
+}
+
+func defaultAssistSettings() assistSettings {
+	return assistSettings{GameSpeed: maxGameSpeed}
+}
+
+func (assist assistSettings) clamped() assistSettings {
+	assist.GameSpeed = assist.gameSpeed()
+	assist.HitboxPadding = min(maxHitboxAssist, max(0, assist.HitboxPadding/hitboxAssistStep*hitboxAssistStep))
+	return assist
+}
+
+func (assist assistSettings) gameSpeed() int {
+	if assist.GameSpeed == 0 {
+		return maxGameSpeed
+	}
+	speed := assist.GameSpeed / gameSpeedStep * gameSpeedStep
+	return min(maxGameSpeed, max(minGameSpeed, speed))
+}
+
+func (assist assistSettings) active() bool {
+	return assist.gameSpeed() < maxGameSpeed || assist.AutoFire || assist.HitboxPadding > 0 || assist.Invincible
+}
+
+// assist returns the assists that change the simulation. Online games ignore
+// them, since both machines must simulate the same rules.
+func (g *Game) assist() assistSettings {
+	if g.net != nil {
+		return defaultAssistSettings()
+	}
+	return g.settings.Assist.clamped()
+}
+
+// applyGameSpeed slows the whole simulation, so enemies, shots and timers all
+// keep their usual pace relative to each other.
+func (g *Game) applyGameSpeed() {
+	speed := maxGameSpeed
+	if g.state == statePlaying {
+		speed = g.assist().gameSpeed()
+	}
+	ebiten.SetTPS(ebiten.DefaultTPS * speed / maxGameSpeed)
+}
+
+func (g *Game) assistMenuItems() []menuItem {
+	assist := &g.settings.Assist
+	return []menuItem{
+		{
+			label: "assist.gameSpeed",
+			value: func() string { return fmt.Sprintf("< %3d%% >", assist.gameSpeed()) },
+			adjust: func(delta int) {
+				assist.GameSpeed = min(maxGameSpeed, max(minGameSpeed, assist.gameSpeed()+delta*gameSpeedStep))
+				g.saveSettings()
+			},
+		},
+		g.toggleItem("assist.autoFire", &assist.AutoFire),
+		{
+			label: "assist.hitbox",
+			value: func() string {
+				if assist.HitboxPadding == 0 {
+					return g.message("assist.hitboxNormal")
+				}
+				return g.message("assist.hitboxSmaller", assist.HitboxPadding)
+			},
+			adjust: func(delta int) {
+				assist.HitboxPadding = min(maxHitboxAssist, max(0, assist.HitboxPadding+delta*hitboxAssistStep))
+				g.saveSettings()
+			},
+		},
+		g.toggleItem("assist.invincible", &assist.Invincible),
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestAssistedRunsDoNotSetHighScores(t *testing.T) {
+	store := &fakeHighScoreStore{}
+	game := &Game{world: &sim.World{Score: 25}, highScore: 10, highScoreStore: store, assisted: true}
+	game.updateHighScore()
+	if game.highScore != 10 || len(store.saved) != 0 {
+		t.Fatalf("high score = %d with saves %v, want the old record untouched", game.highScore, store.saved)
+	}
+}
+
+func TestStartRunMarksAssistedRuns(t *testing.T) {
+	game := &Game{}
+	game.startRun()
+	if game.assisted {
+		t.Fatal("run without assists was marked as assisted")
+	}
+	game.settings.Assist.AutoFire = true
+	game.startRun()
+	if !game.assisted {
+		t.Fatal("run with auto-fire was not marked as assisted")
+	}
+}
+
+func TestAssistSettingsClamp(t *testing.T) {
+	tests := []struct {
+		in   assistSettings
+		want assistSettings
+	}{
+		{assistSettings{}, assistSettings{GameSpeed: 100}},
+		{assistSettings{GameSpeed: 10, HitboxPadding: 50}, assistSettings{GameSpeed: 50, HitboxPadding: 8}},
+		{assistSettings{GameSpeed: 75, HitboxPadding: 5}, assistSettings{GameSpeed: 70, HitboxPadding: 4}},
+	}
+	for _, test := range tests {
+		if got := test.in.clamped(); got != test.want {
+			t.Errorf("clamped(%+v) = %+v, want %+v", test.in, got, test.want)
+		}
+	}
+	if (assistSettings{GameSpeed: 100}).active() || !(assistSettings{GameSpeed: 90}).active() {
+		t.Fatal("only a slowed game speed should count as an assist")
+	}
+}
+
+func TestGameSpeedMenuStaysInRange(t *testing.T) {
+	game := &Game{}
+	speed := game.assistMenuItems()[0]
+	speed.adjust(1)
+	if game.settings.Assist.gameSpeed() != maxGameSpeed {
+		t.Fatalf("speed = %d, want it capped at %d", game.settings.Assist.gameSpeed(), maxGameSpeed)
+	}
+	for range 10 {
+		speed.adjust(-1)
+	}
+	if game.settings.Assist.gameSpeed() != minGameSpeed {
+		t.Fatalf("speed = %d, want it floored at %d", game.settings.Assist.gameSpeed(), minGameSpeed)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/bot"
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	attractIdleTime = 20 * 60 // Ticks on the title without input before the demo starts.
+	attractTime     = 60 * 60 // Longest demo before it goes back to the title.
+)
+
+// attractSkill is the bot that plays the title demo and the debug autopilot.
+var attractSkill = bot.Skills[len(bot.Skills)-1]
+
+// attractDemo is the title screen's demo: a bot playing a silent Normal game
+// of its own. Nothing it does reaches the high score, achievements or stats.
+type attractDemo struct {
+	idleTicks int // Ticks on the title since the last input.
+	ticks     int // Ticks the demo has been running.
+	bot       *bot.Bot
+}
+
+// updateTitleIdle counts ticks on the title without input and starts the
+// demo once the player has left it alone for attractIdleTime.
+func (g *Game) updateTitleIdle(input bool) bool {
+	if input {
+		g.attract.idleTicks = 0
+		return false
+	}
+	g.attract.idleTicks++
+	if g.attract.idleTicks < attractIdleTime {
+		return false
+	}
+	g.startAttract(time.Now().UnixNano())
+	return true
+}
+
+func (g *Game) startAttract(seed int64) {
+	g.world = sim.New(sim.Config{Tuning: tuning, Seed: seed}, g.sizes)
+	g.attract = attractDemo{bot: bot.New(attractSkill, seed)}
+	g.waveBannerTicks = waveBannerTime
+	g.state = stateAttract
+}
+
+// updateAttract plays one tick of the demo. Any input, the bot losing or the
+// demo running its course goes back to the title.
+func (g *Game) updateAttract(input bool) {
+	if input || g.world.Over || g.attract.ticks >= attractTime {
+		g.stopAttract()
+		return
+	}
+	g.attract.ticks++
+	g.updateWaveBanner()
+	g.world.Step([2]sim.Controls{g.attract.bot.Controls(g.world, sim.PlayerOne)})
+}
+
+func (g *Game) stopAttract() {
+	g.world = sim.New(sim.Config{Tuning: tuning}, g.sizes)
+	g.attract = attractDemo{}
+	g.state = stateTitle
+}
+
+func (g *Game) drawAttract(screen *ebiten.Image) {
+	g.drawGame(screen)
+	g.drawCenteredText(screen, g.message("attract.demo"), screenHeight/2, color.RGBA{R: 255, G: 220, B: 70, A: 255})
+	g.drawCenteredText(screen, g.message("attract.back"), screenHeight/2+40, color.White)
+}
+
+// toggleAutopilot hands player one to the demo bot in debug mode. A run the
+// bot has played is marked as assisted so it cannot set records.
+func (g *Game) toggleAutopilot() {
+	if g.autopilot != nil {
+		g.autopilot = nil
+		log.Printf("debug: autopilot off")
+		return
+	}
+	g.autopilot = bot.New(attractSkill, time.Now().UnixNano())
+	g.assisted = true
+	log.Printf("debug: autopilot on (%s)", attractSkill.Name)
+}
+
+// anyInputJustPressed reports a key, mouse button, touch or gamepad button
+// pressed this tick.
+func anyInputJustPressed() bool {
+	if len(inpututil.AppendJustPressedKeys(nil)) > 0 || len(inpututil.AppendJustPressedTouchIDs(nil)) > 0 {
+		return true
+	}
+	for button := ebiten.MouseButton0; button <= ebiten.MouseButtonMax; button++ {
+		if inpututil.IsMouseButtonJustPressed(button) {
+			return true
+		}
+	}
+	for _, pad := range ebiten.AppendGamepadIDs(nil) {
+		for button := ebiten.GamepadButton0; button <= ebiten.GamepadButtonMax; button++ {
+			if inpututil.IsGamepadButtonJustPressed(pad, button) {
+				return true
+			}
+		}
+	}
+	return false
+}
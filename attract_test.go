@@ -0,0 +1,86 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+var gameSizes = sim.Sizes{
+	Player:     image.Pt(797, 1984),
+	UFO:        image.Pt(39, 31),
+	Projectile: image.Pt(10, 10),
+	BashiHebi:  image.Pt(40, 59),
+	Ebi:        image.Pt(30, 34),
+	Boss:       image.Pt(1254, 1254),
+}
+
+func TestTitleStartsTheDemoAfterIdleTime(t *testing.T) {
+	g := &Game{sizes: gameSizes}
+	for range attractIdleTime - 1 {
+		if g.updateTitleIdle(false) {
+			t.Fatal("demo started before the idle time")
+		}
+	}
+	g.updateTitleIdle(true)
+	g.updateTitleIdle(false)
+	if g.state == stateAttract {
+		t.Fatal("input on the title did not restart the idle time")
+	}
+	for range attractIdleTime {
+		g.updateTitleIdle(false)
+	}
+	if g.state != stateAttract || g.world == nil || g.attract.bot == nil {
+		t.Fatalf("state = %d after %d idle ticks, want the demo", g.state, attractIdleTime)
+	}
+}
+
+func TestDemoPlaysSilentlyAndInputReturnsToTitle(t *testing.T) {
+	g := &Game{sizes: gameSizes}
+	g.startAttract(1)
+	for range 600 {
+		g.updateAttract(false)
+	}
+	if g.state != stateAttract || g.world.Score == 0 {
+		t.Fatalf("state = %d score = %d after 10 seconds of demo, want the bot scoring", g.state, g.world.Score)
+	}
+	if g.highScore != 0 || len(g.unlocked) != 0 || g.stats.Runs != 0 {
+		t.Fatal("the demo reached the high score, achievements or stats")
+	}
+	g.updateAttract(true)
+	if g.state != stateTitle || g.world.Score != 0 || g.attract.bot != nil {
+		t.Fatal("input did not return to a fresh title")
+	}
+}
+
+func TestDemoHUDShowsTheDifficultyItPlays(t *testing.T) {
+	g := &Game{sizes: gameSizes, mode: modeDaily}
+	g.settings.Difficulty = sim.DifficultyLunatic
+	g.startAttract(1)
+	if got, want := g.worldDifficultyName(), g.message("difficulty.normal"); got != want {
+		t.Fatalf("demo HUD difficulty = %q, want %q", got, want)
+	}
+}
+
+func TestDemoEndsAfterAttractTime(t *testing.T) {
+	g := &Game{sizes: gameSizes}
+	g.startAttract(1)
+	g.attract.ticks = attractTime
+	g.updateAttract(false)
+	if g.state != stateTitle {
+		t.Fatal("demo kept running past attractTime")
+	}
+}
+
+func TestAutopilotMarksTheRunAssisted(t *testing.T) {
+	g := &Game{}
+	g.toggleAutopilot()
+	if g.autopilot == nil || !g.assisted {
+		t.Fatal("autopilot did not take over an assisted run")
+	}
+	g.toggleAutopilot()
+	if g.autopilot != nil {
+		t.Fatal("autopilot stayed on")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	volumeStep       = 10
+	maxVolume        = 100
+	duckedBGMLevel   = 0.3
+	duckAttackStep   = 0.1  // Per tick, so the music drops within a few frames.
+	duckReleaseStep  = 0.02 // Per tick, so the music swells back over about half a second.
+	muteButtonSize   = 24
+	muteButtonMargin = 6
+)
+
+type audioChannel uint8
+
+const (
+	channelBGM audioChannel = iota
+	channelSFX
+)
+
+// audioSettings are volume percentages from 0 to maxVolume.
+type audioSettings struct {
+	Master int  `json:"master"`
+	BGM    int  `json:"bgm"`
+	SFX    int  `json:"sfx"`
+	Muted  bool `json:"muted"`
+}
+
+func defaultAudioSettings() audioSettings {
+	return audioSettings{Master: maxVolume, BGM: maxVolume, SFX: maxVolume}
+}
+
+func (settings audioSettings) clamped() audioSettings {
+	settings.Master = clampVolume(settings.Master)
+	settings.BGM = clampVolume(settings.BGM)
+	settings.SFX = clampVolume(settings.SFX)
+	return settings
+}
+
+func clampVolume(volume int) int {
+	return min(maxVolume, max(0, volume))
+}
+
+// volume is the player volume for a channel before ducking.
+func (settings audioSettings) volume(channel audioChannel) float64 {
+	if settings.Muted {
+		return 0
+	}
+	level := settings.SFX
+	if channel == channelBGM {
+		level = settings.BGM
+	}
+	return float64(clampVolume(settings.Master)) / maxVolume * float64(clampVolume(level)) / maxVolume
+}
+
+// newAudioContext reuses the process-wide context when one exists, since
+// Ebitengine allows only one and tests may construct several games.
+func newAudioContext() *audio.Context {
+	if context := audio.CurrentContext(); context != nil {
+		return context
+	}
+	return audio.NewContext(audioSampleRate)
+}
+
+// audioMixer applies the volume settings to every player once per tick and
+// ducks the music while the KIEE special, the game-over jingle or the boss
+// victory sting plays.
+type audioMixer struct {
+	duck float64
+}
+
+func newAudioMixer() audioMixer {
+	return audioMixer{duck: 1}
+}
+
+func (mixer *audioMixer) updateDuck(ducking bool) {
+	if ducking {
+		mixer.duck = max(duckedBGMLevel, mixer.duck-duckAttackStep)
+		return
+	}
+	mixer.duck = min(1, mixer.duck+duckReleaseStep)
+}
+
+func (g *Game) updateAudio() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) || g.muteButtonPressed() {
+		g.toggleMute()
+	}
+
+	g.mixer.updateDuck(g.kieeSound.isPlaying() || g.kieeSound2.isPlaying() || g.gameOverSE.isPlaying() || g.music.stingPlaying())
+	g.music.update(g.world.Level(), sim.IsBossWave(g.world.Wave), g.settings.Audio.volume(channelBGM)*g.mixer.duck)
+	sfxVolume := g.settings.Audio.volume(channelSFX)
+	for _, asset := range g.soundAssets() {
+		(*asset.effect).setVolume(sfxVolume)
+	}
+}
+
+func (g *Game) toggleMute() {
+	g.settings.Audio.Muted = !g.settings.Audio.Muted
+	g.saveSettings()
+}
+
+func muteButtonRect() image.Rectangle {
+	x := screenWidth - muteButtonMargin - muteButtonSize
+	return image.Rect(x, muteButtonMargin, x+muteButtonSize, muteButtonMargin+muteButtonSize)
+}
+
+// muteButtonPressed reports a click or tap on the speaker button. Touches that
+// start on the button are also left out of gameplayTouchIDs so they do not
+// shoot or start the game.
+func (g *Game) muteButtonPressed() bool {
+	button := muteButtonRect()
+	for _, position := range g.justPressedPointerPositions() {
+		if position.In(button) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) drawMuteButton(screen *ebiten.Image) {
+	button := muteButtonRect()
+	x := float32(button.Min.X)
+	y := float32(button.Min.Y)
+	const size = muteButtonSize
+	ebitenutil.DrawRect(screen, float64(x), float64(y), size, size, color.RGBA{R: 20, G: 20, B: 40, A: 180})
+
+	iconColor := color.RGBA{R: 230, G: 235, B: 255, A: 255}
+	var speaker vector.Path
+	speaker.MoveTo(x+4, y+9)
+	speaker.LineTo(x+8, y+9)
+	speaker.LineTo(x+13, y+4)
+	speaker.LineTo(x+13, y+20)
+	speaker.LineTo(x+8, y+15)
+	speaker.LineTo(x+4, y+15)
+	speaker.Close()
+	drawOptions := &vector.DrawPathOptions{AntiAlias: true}
+	drawOptions.ColorScale.ScaleWithColor(iconColor)
+	vector.FillPath(screen, &speaker, nil, drawOptions)
+
+	if g.settings.Audio.Muted {
+		muteColor := color.RGBA{R: 255, G: 90, B: 80, A: 255}
+		vector.StrokeLine(screen, x+15, y+8, x+21, y+16, 2, muteColor, true)
+		vector.StrokeLine(screen, x+21, y+8, x+15, y+16, 2, muteColor, true)
+		return
+	}
+	vector.StrokeLine(screen, x+16, y+9, x+16, y+15, 2, iconColor, true)
+	vector.StrokeLine(screen, x+19, y+6, x+19, y+18, 2, iconColor, true)
+}
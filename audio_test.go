@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestAudioSettingsVolumeCombinesMasterAndChannel(t *testing.T) {
+	settings := audioSettings{Master: 50, BGM: 40, SFX: 100}
+	if got := settings.volume(channelBGM); got != 0.2 {
+		t.Fatalf("BGM volume = %v, want 0.2", got)
+	}
+	if got := settings.volume(channelSFX); got != 0.5 {
+		t.Fatalf("SFX volume = %v, want 0.5", got)
+	}
+
+	settings.Muted = true
+	if got := settings.volume(channelSFX); got != 0 {
+		t.Fatalf("muted SFX volume = %v, want 0", got)
+	}
+
+	loud := audioSettings{Master: 250, BGM: -10, SFX: 100}.clamped()
+	if loud.Master != maxVolume || loud.BGM != 0 {
+		t.Fatalf("clamped settings = %+v, want master %d and BGM 0", loud, maxVolume)
+	}
+}
+
+func TestAudioMixerDucksQuicklyAndRecoversSlowly(t *testing.T) {
+	mixer := newAudioMixer()
+	attackTicks := 0
+	for mixer.duck > duckedBGMLevel {
+		mixer.updateDuck(true)
+		attackTicks++
+	}
+	releaseTicks := 0
+	for mixer.duck < 1 {
+		mixer.updateDuck(false)
+		releaseTicks++
+	}
+	if attackTicks >= releaseTicks {
+		t.Fatalf("duck attack took %d ticks and release %d, want a faster attack", attackTicks, releaseTicks)
+	}
+
+	mixer.updateDuck(false)
+	if mixer.duck != 1 {
+		t.Fatalf("duck after release = %v, want 1", mixer.duck)
+	}
+}
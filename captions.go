@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	captionTime     = 120
+	maxCaptions     = 3
+	captionLift     = 64 // Keeps captions above the player's ship.
+	captionPadding  = 4
+	captionFadeTime = 20
+)
+
+// caption is an on-screen cue for a sound, shown when captions are enabled.
+type caption struct {
+	key   string
+	ticks int
+}
+
+// showCaption adds or refreshes the cue for a sound event.
+func (g *Game) showCaption(key string) {
+	if !g.settings.Accessibility.Captions {
+		return
+	}
+	for index := range g.captions {
+		if g.captions[index].key == key {
+			g.captions[index].ticks = captionTime
+			return
+		}
+	}
+	if len(g.captions) == maxCaptions {
+		g.captions = removeAt(g.captions, 0)
+	}
+	g.captions = append(g.captions, caption{key: key, ticks: captionTime})
+}
+
+// effectEvent shows the captions and screen flashes for gameplay events.
+func (g *Game) effectEvent(event sim.Event) {
+	switch event := event.(type) {
+	case sim.EbiHit:
+		g.showCaption("caption.ebiHit")
+	case sim.BossDefeated:
+		g.flash()
+		g.showCaption("caption.bossDefeated")
+	case sim.BossAttacked:
+		g.showCaption("caption.bossAttack")
+	case sim.WaveStarted:
+		g.waveBannerTicks = waveBannerTime
+	case sim.PowerUpCollected:
+		g.showCaption("caption.powerUp")
+	case sim.PlayerDied:
+		if event.Down {
+			g.showCaption("caption.playerDown")
+		}
+	case sim.PlayerRevived:
+		g.showCaption("caption.revived")
+	case sim.SpecialUsed:
+		g.flash()
+		g.showCaption("caption.special")
+	}
+}
+
+func (g *Game) updateCaptions() {
+	for index := len(g.captions) - 1; index >= 0; index-- {
+		g.captions[index].ticks--
+		if g.captions[index].ticks <= 0 {
+			g.captions = removeAt(g.captions, index)
+		}
+	}
+}
+
+// drawCaptions stacks the newest caption at the bottom, on a dark box so it
+// reads over any background.
+func (g *Game) drawCaptions(screen *ebiten.Image) {
+	stack := g.newHUDStack(screen, anchorBottomCenter)
+	stack.used = captionLift * stack.scale
+	face := stack.face(fontMedium)
+	padding := captionPadding * stack.scale
+	for index := len(g.captions) - 1; index >= 0; index-- {
+		item := g.captions[index]
+		message := g.message(item.key)
+		width, height := measureText(face, message)
+		x, y := stack.row(width+2*padding, height+2*padding)
+		alpha := min(1, float64(item.ticks)/captionFadeTime)
+		ebitenutil.DrawRect(screen, x, y, width+2*padding, height+2*padding, color.NRGBA{A: uint8(190 * alpha)})
+		drawTextAt(screen, message, face, x+padding, y+padding, color.NRGBA{R: 255, G: 255, B: 255, A: uint8(255 * alpha)})
+	}
+}
@@ -0,0 +1,314 @@
+// Command balance plays headless games with bots of each skill and reports,
+// for every wave, how many runs reached and cleared it, how long clearing
+// took, how often KIEE was used and how much of the wave was powered up. Run
+// it before and after a tuning change to see what the change does:
+//
+//	go run ./cmd/balance -runs 1000 -tuning tuning.json -csv balance.csv -html balance.html
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Kenshu-Miura/mygame/internal/bot"
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const ticksPerSecond = 60
+
+func main() {
+	runs := flag.Int("runs", 200, "games per skill")
+	skills := flag.String("skills", "novice,average,expert", "comma-separated bot skills")
+	difficulty := flag.String("difficulty", "normal", "easy, normal, hard or lunatic")
+	mode := flag.String("mode", "waves", "waves, scoreAttack, survival or bossRush")
+	tuningPath := flag.String("tuning", "", "tuning.json to play with instead of the defaults")
+	assets := flag.String("assets", ".", "directory with the sprites, for their sizes")
+	seed := flag.Int64("seed", 1, "seed of the first game; game n uses seed+n")
+	maxTicks := flag.Int("max-ticks", 30*60*ticksPerSecond, "ticks after which a game is stopped")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at once")
+	csvPath := flag.String("csv", "", "write the CSV here instead of to stdout")
+	htmlPath := flag.String("html", "", "also write an HTML report here")
+	flag.Parse()
+
+	config, err := sim.LoadConfig(*difficulty, *mode, *tuningPath)
+	if err != nil {
+		log.Fatalf("balance: %v", err)
+	}
+	players, err := parseSkills(*skills)
+	if err != nil {
+		log.Fatalf("balance: %v", err)
+	}
+	sizes, err := sim.LoadSizes(os.DirFS(*assets))
+	if err != nil {
+		log.Fatalf("balance: %v", err)
+	}
+
+	batch := batch{config: config, sizes: sizes, runs: *runs, seed: *seed, maxTicks: *maxTicks}
+	var reports []skillReport
+	for _, skill := range players {
+		log.Printf("balance: playing %d games as %s", *runs, skill.Name)
+		reports = append(reports, summarize(skill.Name, batch.play(skill, *workers)))
+	}
+
+	out := io.Writer(os.Stdout)
+	if *csvPath != "" {
+		file, err := os.Create(*csvPath)
+		if err != nil {
+			log.Fatalf("balance: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeCSV(out, reports); err != nil {
+		log.Fatalf("balance: write CSV: %v", err)
+	}
+	if *htmlPath != "" {
+		if err := writeHTMLFile(*htmlPath, batch, reports); err != nil {
+			log.Fatalf("balance: write HTML: %v", err)
+		}
+	}
+}
+
+func parseSkills(names string) ([]bot.Skill, error) {
+	var skills []bot.Skill
+	for name := range strings.SplitSeq(names, ",") {
+		skill, ok := bot.SkillNamed(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown skill %q", name)
+		}
+		skills = append(skills, skill)
+	}
+	return skills, nil
+}
+
+// batch is a set of games that differ only in their seed.
+type batch struct {
+	config   sim.Config
+	sizes    sim.Sizes
+	runs     int
+	seed     int64
+	maxTicks int
+}
+
+// waveRun is what one game did in one wave.
+type waveRun struct {
+	wave         int
+	ticks        int
+	cleared      bool
+	kieeUses     int
+	powerUpTicks int
+}
+
+// play runs the batch with a bot of the given skill. The results are in seed
+// order however many workers there are, so the report is reproducible.
+func (batch batch) play(skill bot.Skill, workers int) [][]waveRun {
+	results := make([][]waveRun, batch.runs)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(1, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range jobs {
+				results[run] = batch.playOne(skill, batch.seed+int64(run))
+			}
+		}()
+	}
+	for run := range batch.runs {
+		jobs <- run
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// playOne plays a single game until it is over or runs out of ticks. A wave
+// counts as cleared when the game moves on to another one.
+func (batch batch) playOne(skill bot.Skill, seed int64) []waveRun {
+	var current waveRun
+	config := batch.config
+	config.Seed = seed
+	config.OnEvent = func(event sim.Event) {
+		if _, ok := event.(sim.SpecialUsed); ok {
+			current.kieeUses++
+		}
+	}
+	world := sim.New(config, batch.sizes)
+	player := bot.New(skill, seed)
+	current.wave = world.Wave
+
+	var waves []waveRun
+	for range batch.maxTicks {
+		world.Step([2]sim.Controls{player.Controls(world, sim.PlayerOne)})
+		current.ticks++
+		if world.PowerUpTicks > 0 {
+			current.powerUpTicks++
+		}
+		if world.Over {
+			break
+		}
+		if world.Wave != current.wave {
+			current.cleared = true
+			waves = append(waves, current)
+			current = waveRun{wave: world.Wave}
+		}
+	}
+	return append(waves, current)
+}
+
+// waveReport adds up one wave over every game of a skill.
+type waveReport struct {
+	Wave            int
+	Reached         int
+	Cleared         int
+	ClearRate       float64 // Of the games that reached the wave.
+	AvgClearSeconds float64 // Of the games that cleared it.
+	KIEEPerRun      float64
+	PowerUpUptime   float64 // Share of the ticks spent in the wave.
+}
+
+type skillReport struct {
+	Skill    string
+	Runs     int
+	AvgWaves float64 // Waves reached per game.
+	Waves    []waveReport
+}
+
+func summarize(skill string, runs [][]waveRun) skillReport {
+	type totals struct {
+		reached, cleared, clearTicks, kiee, powerUp, ticks int
+	}
+	byWave := map[int]*totals{}
+	lastWave := 0
+	reached := 0
+	for _, waves := range runs {
+		reached += len(waves)
+		for _, run := range waves {
+			sum := byWave[run.wave]
+			if sum == nil {
+				sum = &totals{}
+				byWave[run.wave] = sum
+			}
+			sum.reached++
+			sum.kiee += run.kieeUses
+			sum.powerUp += run.powerUpTicks
+			sum.ticks += run.ticks
+			if run.cleared {
+				sum.cleared++
+				sum.clearTicks += run.ticks
+			}
+			lastWave = max(lastWave, run.wave)
+		}
+	}
+
+	report := skillReport{Skill: skill, Runs: len(runs)}
+	if len(runs) > 0 {
+		report.AvgWaves = float64(reached) / float64(len(runs))
+	}
+	for wave := 1; wave <= lastWave; wave++ {
+		sum := byWave[wave]
+		if sum == nil {
+			continue
+		}
+		row := waveReport{
+			Wave:          wave,
+			Reached:       sum.reached,
+			Cleared:       sum.cleared,
+			ClearRate:     float64(sum.cleared) / float64(sum.reached),
+			KIEEPerRun:    float64(sum.kiee) / float64(sum.reached),
+			PowerUpUptime: float64(sum.powerUp) / float64(max(1, sum.ticks)),
+		}
+		if sum.cleared > 0 {
+			row.AvgClearSeconds = float64(sum.clearTicks) / float64(sum.cleared) / ticksPerSecond
+		}
+		report.Waves = append(report.Waves, row)
+	}
+	return report
+}
+
+func writeCSV(out io.Writer, reports []skillReport) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"skill", "wave", "reached", "cleared", "clear_rate", "avg_clear_seconds", "kiee_per_run", "powerup_uptime"})
+	for _, report := range reports {
+		for _, row := range report.Waves {
+			writer.Write([]string{
+				report.Skill,
+				strconv.Itoa(row.Wave),
+				strconv.Itoa(row.Reached),
+				strconv.Itoa(row.Cleared),
+				strconv.FormatFloat(row.ClearRate, 'f', 3, 64),
+				strconv.FormatFloat(row.AvgClearSeconds, 'f', 1, 64),
+				strconv.FormatFloat(row.KIEEPerRun, 'f', 2, 64),
+				strconv.FormatFloat(row.PowerUpUptime, 'f', 3, 64),
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(value float64) string { return fmt.Sprintf("%.1f%%", 100*value) },
+	"fixed":   func(value float64) string { return fmt.Sprintf("%.1f", value) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>mygame balance</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: right; }
+th { background: #eee; }
+</style>
+</head>
+<body>
+<h1>mygame balance</h1>
+<p>Difficulty {{.Difficulty}}, mode {{.Mode}}, seeds {{.FirstSeed}} to {{.LastSeed}}.</p>
+{{range .Reports}}
+<h2>{{.Skill}}</h2>
+<p>{{.Runs}} games, {{fixed .AvgWaves}} waves reached per game.</p>
+<table>
+<tr><th>Wave</th><th>Reached</th><th>Cleared</th><th>Clear rate</th><th>Avg clear (s)</th><th>KIEE per run</th><th>Power-up uptime</th></tr>
+{{range .Waves}}<tr><td>{{.Wave}}</td><td>{{.Reached}}</td><td>{{.Cleared}}</td><td>{{percent .ClearRate}}</td><td>{{fixed .AvgClearSeconds}}</td><td>{{printf "%.2f" .KIEEPerRun}}</td><td>{{percent .PowerUpUptime}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+func writeHTML(out io.Writer, batch batch, reports []skillReport) error {
+	difficulty, mode := string(batch.config.Difficulty), string(batch.config.Mode)
+	if difficulty == "" {
+		difficulty = "normal"
+	}
+	if mode == "" {
+		mode = "waves"
+	}
+	return reportTemplate.Execute(out, struct {
+		Difficulty, Mode    string
+		FirstSeed, LastSeed int64
+		Reports             []skillReport
+	}{difficulty, mode, batch.seed, batch.seed + int64(batch.runs) - 1, reports})
+}
+
+func writeHTMLFile(path string, batch batch, reports []skillReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeHTML(file, batch, reports); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/bot"
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func testBatch() batch {
+	return batch{
+		config: sim.Config{Tuning: sim.DefaultTuning()},
+		sizes: sim.Sizes{
+			Player:     image.Pt(797, 1984),
+			UFO:        image.Pt(39, 31),
+			Projectile: image.Pt(10, 10),
+			BashiHebi:  image.Pt(40, 59),
+			Ebi:        image.Pt(30, 34),
+			Boss:       image.Pt(1254, 1254),
+		},
+		runs:     8,
+		seed:     1,
+		maxTicks: 10 * 60 * ticksPerSecond,
+	}
+}
+
+func TestPlayIsReproducible(t *testing.T) {
+	skill, _ := bot.SkillNamed("average")
+	batch := testBatch()
+	if first, second := batch.play(skill, 1), batch.play(skill, 4); !reflect.DeepEqual(first, second) {
+		t.Fatal("the same seeds played differently with more workers")
+	}
+}
+
+func TestPlayOneClearsWavesInOrder(t *testing.T) {
+	skill, _ := bot.SkillNamed("expert")
+	waves := testBatch().playOne(skill, 1)
+	for index, run := range waves {
+		if run.wave != index+1 || run.cleared != (index < len(waves)-1) || run.ticks <= 0 {
+			t.Fatalf("waves = %+v, want waves 1 to %d with only the last one lost", waves, len(waves))
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	runs := [][]waveRun{
+		{{wave: 1, ticks: 600, cleared: true, powerUpTicks: 300}, {wave: 2, ticks: 100, kieeUses: 1}},
+		{{wave: 1, ticks: 1200, cleared: true}, {wave: 2, ticks: 300, cleared: true, kieeUses: 1}, {wave: 3, ticks: 50}},
+	}
+	report := summarize("expert", runs)
+	want := []waveReport{
+		{Wave: 1, Reached: 2, Cleared: 2, ClearRate: 1, AvgClearSeconds: 15, PowerUpUptime: 300.0 / 1800},
+		{Wave: 2, Reached: 2, Cleared: 1, ClearRate: 0.5, AvgClearSeconds: 5, KIEEPerRun: 1},
+		{Wave: 3, Reached: 1},
+	}
+	if report.Runs != 2 || report.AvgWaves != 2.5 || !reflect.DeepEqual(report.Waves, want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+}
+
+func TestReportsListEveryWave(t *testing.T) {
+	reports := []skillReport{summarize("novice", [][]waveRun{{{wave: 1, ticks: 60, cleared: true}, {wave: 2, ticks: 30}}})}
+	var out bytes.Buffer
+	if err := writeCSV(&out, reports); err != nil {
+		t.Fatal(err)
+	}
+	want := "skill,wave,reached,cleared,clear_rate,avg_clear_seconds,kiee_per_run,powerup_uptime\n" +
+		"novice,1,1,1,1.000,1.0,0.00,0.000\n" +
+		"novice,2,1,0,0.000,0.0,0.00,0.000\n"
+	if out.String() != want {
+		t.Fatalf("CSV =\n%s\nwant\n%s", out.String(), want)
+	}
+	out.Reset()
+	if err := writeHTML(&out, testBatch(), reports); err != nil {
+		t.Fatal(err)
+	}
+	if html := out.String(); !strings.Contains(html, "<h2>novice</h2>") || !strings.Contains(html, "<td>100.0%</td>") || !strings.Contains(html, "seeds 1 to 8") {
+		t.Fatalf("HTML report is missing the table:\n%s", html)
+	}
+}
+
+func TestParseSkillsRejectsUnknownNames(t *testing.T) {
+	if _, err := parseSkills("expert,pro"); err == nil {
+		t.Fatal("unknown skill accepted")
+	}
+}
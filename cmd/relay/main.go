@@ -0,0 +1,143 @@
+// Command relay pairs up game clients that join the same room and forwards
+// every message between them, so two players can play online co-op or
+// versus. The game runs the simulation; the relay only assigns the player
+// numbers and the shared random seed.
+//
+//	go run ./cmd/relay -addr localhost:8765
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/websocket"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8765", "address to listen on")
+	flag.Parse()
+
+	http.Handle("/relay", newRelay())
+	log.Printf("relay: listening on ws://%s/relay", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// client is one connected game. peer is set once a second client joins the
+// same room.
+type client struct {
+	conn *websocket.Conn
+	room string
+	peer *client
+	// sending orders the writes to conn, so nothing forwarded overtakes the
+	// hello, without holding the relay's lock while a slow client reads.
+	sending sync.Mutex
+}
+
+func (c *client) send(message []byte) {
+	c.sending.Lock()
+	defer c.sending.Unlock()
+	c.conn.WriteMessage(message)
+}
+
+type relay struct {
+	mu      sync.Mutex
+	waiting map[string]*client
+	seed    func() int64
+}
+
+func newRelay() *relay {
+	return &relay{waiting: map[string]*client{}, seed: rand.Int63}
+}
+
+func (relay *relay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r)
+	if err != nil {
+		log.Printf("relay: %v", err)
+		return
+	}
+	room := r.URL.Query().Get("room")
+	if room == "" {
+		room = "default"
+	}
+	joined := &client{conn: conn, room: room}
+	relay.join(joined)
+	defer relay.leave(joined)
+
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if peer := relay.peerOf(joined); peer != nil {
+			peer.send(message)
+		}
+	}
+}
+
+// join waits in the room or, if someone is already waiting there, pairs the
+// two clients and greets them with their player numbers and a shared seed.
+func (relay *relay) join(joined *client) {
+	relay.mu.Lock()
+	host, ok := relay.waiting[joined.room]
+	if !ok {
+		relay.waiting[joined.room] = joined
+		relay.mu.Unlock()
+		log.Printf("relay: waiting in room %q", joined.room)
+		return
+	}
+	delete(relay.waiting, joined.room)
+	host.peer, joined.peer = joined, host
+	seed := relay.seed()
+	// Neither client has a peer writing to it yet, so these do not wait.
+	host.sending.Lock()
+	joined.sending.Lock()
+	relay.mu.Unlock()
+
+	host.conn.WriteMessage(helloMessage(0, seed))
+	host.sending.Unlock()
+	joined.conn.WriteMessage(helloMessage(1, seed))
+	joined.sending.Unlock()
+	log.Printf("relay: paired room %q", joined.room)
+}
+
+// leaveTimeout is how long a partner that was told the game ended has to
+// close its side. Closing it here instead could reset the connection before
+// the notice is read.
+const leaveTimeout = 10 * time.Second
+
+// leave drops a client that disconnected and tells its partner, whose game
+// then returns to the title and closes the connection.
+func (relay *relay) leave(left *client) {
+	relay.mu.Lock()
+	if relay.waiting[left.room] == left {
+		delete(relay.waiting, left.room)
+	}
+	peer := left.peer
+	if peer != nil {
+		left.peer, peer.peer = nil, nil
+	}
+	relay.mu.Unlock()
+
+	left.conn.Close()
+	if peer == nil {
+		return
+	}
+	peer.send([]byte(`{"type":"left"}`))
+	peer.conn.SetReadDeadline(time.Now().Add(leaveTimeout))
+	log.Printf("relay: closed room %q", left.room)
+}
+
+func (relay *relay) peerOf(sender *client) *client {
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	return sender.peer
+}
+
+func helloMessage(player int, seed int64) []byte {
+	return fmt.Appendf(nil, `{"type":"hello","player":%d,"seed":%d}`, player, seed)
+}
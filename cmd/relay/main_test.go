@@ -0,0 +1,73 @@
+//go:build !js
+
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/websocket"
+)
+
+func dialRoom(t *testing.T, server *httptest.Server, room string) *websocket.Conn {
+	t.Helper()
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(server.URL, "http") + "/relay?room=" + room)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readString(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(message)
+}
+
+func TestRelayPairsRoomAndForwards(t *testing.T) {
+	relay := newRelay()
+	relay.seed = func() int64 { return 42 }
+	server := httptest.NewServer(relay)
+	defer server.Close()
+
+	host := dialRoom(t, server, "coop-1")
+	guest := dialRoom(t, server, "coop-1")
+	if got := readString(t, host); got != `{"type":"hello","player":0,"seed":42}` {
+		t.Fatalf("host hello = %s", got)
+	}
+	if got := readString(t, guest); got != `{"type":"hello","player":1,"seed":42}` {
+		t.Fatalf("guest hello = %s", got)
+	}
+
+	if err := host.WriteMessage([]byte(`{"type":"input","tick":3}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, guest); got != `{"type":"input","tick":3}` {
+		t.Fatalf("guest received %s, want the host's input", got)
+	}
+
+	host.Close()
+	if got := readString(t, guest); got != `{"type":"left"}` {
+		t.Fatalf("guest received %s, want a left notice", got)
+	}
+}
+
+func TestRelayKeepsRoomsApart(t *testing.T) {
+	server := httptest.NewServer(newRelay())
+	defer server.Close()
+
+	coop := dialRoom(t, server, "coop-1")
+	dialRoom(t, server, "versus-1")
+	other := dialRoom(t, server, "coop-1")
+	if got := readString(t, coop); !strings.Contains(got, `"player":0`) {
+		t.Fatalf("co-op host hello = %s", got)
+	}
+	if got := readString(t, other); !strings.Contains(got, `"player":1`) {
+		t.Fatalf("co-op guest hello = %s, want to be paired with the co-op host", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Command rlserver lets training scripts in any language play headless games
+// through the rl environment. Every TCP connection gets a game of its own and
+// sends one JSON request per line:
+//
+//	{"cmd":"spec"}
+//	{"cmd":"reset","seed":1}
+//	{"cmd":"step","action":4}
+//
+// Each request gets one JSON reply per line with the observation, the reward
+// and whether the game is done, or an error.
+//
+//	go run ./cmd/rlserver -addr localhost:5555 -frame-skip 4 -max-steps 5000
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	"github.com/Kenshu-Miura/mygame/internal/rl"
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:5555", "address to listen on")
+	difficulty := flag.String("difficulty", "normal", "easy, normal, hard or lunatic")
+	mode := flag.String("mode", "waves", "waves, scoreAttack, survival or bossRush")
+	tuningPath := flag.String("tuning", "", "tuning.json to play with instead of the defaults")
+	assets := flag.String("assets", ".", "directory with the sprites, for their sizes")
+	frameSkip := flag.Int("frame-skip", 4, "ticks each action is held")
+	maxSteps := flag.Int("max-steps", 0, "steps after which a game is done anyway; 0 for no limit")
+	flag.Parse()
+
+	config, err := sim.LoadConfig(*difficulty, *mode, *tuningPath)
+	if err != nil {
+		log.Fatalf("rlserver: %v", err)
+	}
+	sizes, err := sim.LoadSizes(os.DirFS(*assets))
+	if err != nil {
+		log.Fatalf("rlserver: %v", err)
+	}
+	options := rl.Options{Config: config, Sizes: sizes, FrameSkip: *frameSkip, MaxSteps: *maxSteps}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("rlserver: %v", err)
+	}
+	log.Printf("rlserver: listening on %s", listener.Addr())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Fatalf("rlserver: %v", err)
+		}
+		go func() {
+			defer conn.Close()
+			if err := serve(conn, rl.New(options)); err != nil {
+				log.Printf("rlserver: %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+type request struct {
+	Cmd    string `json:"cmd"`
+	Seed   int64  `json:"seed"`
+	Action int    `json:"action"`
+}
+
+// reply answers one request. Info is there for logging; agents should learn
+// from the observation and the reward alone.
+type reply struct {
+	Observation *rl.Observation `json:"observation,omitempty"`
+	Reward      float64         `json:"reward"`
+	Done        bool            `json:"done"`
+	Info        *info           `json:"info,omitempty"`
+	Spec        *rl.Spec        `json:"spec,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+type info struct {
+	Score int `json:"score"`
+	Wave  int `json:"wave"`
+}
+
+// serve answers requests until the client hangs up. A request the server
+// cannot follow gets an error reply and the game carries on; a line that is
+// not JSON ends the connection.
+func serve(conn io.ReadWriter, env *rl.Env) error {
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var req request
+		if err := decoder.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			encoder.Encode(reply{Error: fmt.Sprintf("read request: %v", err)})
+			return err
+		}
+		if err := encoder.Encode(handle(env, req)); err != nil {
+			return err
+		}
+	}
+}
+
+func handle(env *rl.Env, req request) reply {
+	switch req.Cmd {
+	case "spec":
+		spec := env.Spec()
+		return reply{Spec: &spec}
+	case "reset":
+		observation := env.Reset(req.Seed)
+		return reply{Observation: &observation, Info: worldInfo(env)}
+	case "step":
+		action, err := rl.ParseAction(req.Action)
+		if err != nil {
+			return reply{Error: err.Error()}
+		}
+		observation, reward, done := env.Step(action)
+		return reply{Observation: &observation, Reward: reward, Done: done, Info: worldInfo(env)}
+	}
+	return reply{Error: fmt.Sprintf("unknown cmd %q, want spec, reset or step", req.Cmd)}
+}
+
+func worldInfo(env *rl.Env) *info {
+	return &info{Score: env.World().Score, Wave: env.World().Wave}
+}
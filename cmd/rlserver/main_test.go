@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"image"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/rl"
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func dialServer(t *testing.T) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	sizes := sim.Sizes{
+		Player:     image.Pt(797, 1984),
+		UFO:        image.Pt(39, 31),
+		Projectile: image.Pt(10, 10),
+		BashiHebi:  image.Pt(40, 59),
+		Ebi:        image.Pt(30, 34),
+		Boss:       image.Pt(1254, 1254),
+	}
+	client, server := net.Pipe()
+	env := rl.New(rl.Options{Config: sim.Config{Tuning: sim.DefaultTuning()}, Sizes: sizes, MaxSteps: 2})
+	go func() {
+		defer server.Close()
+		serve(server, env)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client, bufio.NewReader(client)
+}
+
+func ask(t *testing.T, conn net.Conn, reader *bufio.Reader, line string) reply {
+	t.Helper()
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := reader.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var answer reply
+	if err := json.Unmarshal(data, &answer); err != nil {
+		t.Fatalf("reply %s: %v", data, err)
+	}
+	return answer
+}
+
+func TestServerPlaysAGame(t *testing.T) {
+	conn, reader := dialServer(t)
+	spec := ask(t, conn, reader, `{"cmd":"spec"}`)
+	if spec.Spec == nil || len(spec.Spec.Actions) != len(rl.Actions) || spec.Spec.FeatureCount != rl.FeatureCount {
+		t.Fatalf("spec = %+v", spec.Spec)
+	}
+
+	reset := ask(t, conn, reader, `{"cmd":"reset","seed":5}`)
+	if reset.Observation == nil || len(reset.Observation.Frame) != rl.FrameWidth*rl.FrameHeight || reset.Info.Wave != 1 {
+		t.Fatalf("reset reply = %+v", reset)
+	}
+	if step := ask(t, conn, reader, `{"cmd":"step","action":3}`); step.Error != "" || step.Done {
+		t.Fatalf("first step = %+v", step)
+	}
+	if step := ask(t, conn, reader, `{"cmd":"step","action":3}`); !step.Done {
+		t.Fatal("the game went on past -max-steps")
+	}
+}
+
+func TestServerReportsBadRequests(t *testing.T) {
+	conn, reader := dialServer(t)
+	if answer := ask(t, conn, reader, `{"cmd":"step","action":99}`); !strings.Contains(answer.Error, "unknown action") {
+		t.Fatalf("error = %q, want an unknown action", answer.Error)
+	}
+	if answer := ask(t, conn, reader, `{"cmd":"jump"}`); !strings.Contains(answer.Error, "unknown cmd") {
+		t.Fatalf("error = %q, want an unknown cmd", answer.Error)
+	}
+	if answer := ask(t, conn, reader, `{"cmd":"reset","seed":1}`); answer.Error != "" {
+		t.Fatalf("the game did not carry on after bad requests: %q", answer.Error)
+	}
+	if answer := ask(t, conn, reader, `not json`); !strings.Contains(answer.Error, "read request") {
+		t.Fatalf("error = %q, want a read error", answer.Error)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const gamepadDeadZone = 0.5
+
+// coopSettings choose local two-player co-op. Both players share the team
+// score, which keeps high scores and ghosts apart from solo runs; split
+// scores also show each player's share.
+type coopSettings struct {
+	Enabled    bool `json:"enabled"`
+	SplitScore bool `json:"splitScore"`
+}
+
+func (g *Game) coopMenuItems() []menuItem {
+	coop := &g.settings.Coop
+	return []menuItem{
+		{
+			label: "coop.enabled",
+			value: func() string { return g.onOff(coop.Enabled) },
+			adjust: func(int) {
+				coop.Enabled = !coop.Enabled
+				g.saveSettings()
+				g.loadHighScore()
+			},
+		},
+		{
+			label: "coop.score",
+			value: func() string {
+				if coop.SplitScore {
+					return g.message("coop.scoreSplit")
+				}
+				return g.message("coop.scoreShared")
+			},
+			adjust: func(int) {
+				coop.SplitScore = !coop.SplitScore
+				g.saveSettings()
+			},
+		},
+	}
+}
+
+// tickControls reads both players' controls for the next tick. Online, they
+// come from the lockstep session instead.
+func (g *Game) tickControls() [2]sim.Controls {
+	if g.net != nil {
+		return g.net.current
+	}
+	controls := [2]sim.Controls{g.readControls(sim.PlayerOne).Or(g.touchControls())}
+	if g.autopilot != nil {
+		controls[sim.PlayerOne] = g.autopilot.Controls(g.world, sim.PlayerOne)
+	}
+	if g.world.Coop {
+		controls[sim.PlayerTwo] = g.readControls(sim.PlayerTwo)
+	}
+	return controls
+}
+
+// readControls reads the arrow keys and Space for player one and A/D, F and
+// W for player two. Player two also gets the first gamepad, since player one
+// already has the keyboard.
+func (g *Game) readControls(index int) sim.Controls {
+	if index == sim.PlayerOne {
+		return g.keyboardControls(sim.PlayerOne)
+	}
+	return g.keyboardControls(sim.PlayerTwo).Or(gamepadControls())
+}
+
+// localControls are the controls of the one player on this machine in an
+// online game: player one's keys or the gamepad.
+func (g *Game) localControls() sim.Controls {
+	return g.keyboardControls(sim.PlayerOne).Or(gamepadControls())
+}
+
+func (g *Game) keyboardControls(index int) sim.Controls {
+	autoFire := g.assist().AutoFire
+	if index == sim.PlayerOne {
+		return sim.Controls{
+			Left:    ebiten.IsKeyPressed(ebiten.KeyLeft),
+			Right:   ebiten.IsKeyPressed(ebiten.KeyRight),
+			Fire:    ebiten.IsKeyPressed(ebiten.KeySpace) || autoFire,
+			Special: inpututil.IsKeyJustPressed(ebiten.KeyArrowUp),
+		}
+	}
+	return sim.Controls{
+		Left:    ebiten.IsKeyPressed(ebiten.KeyA),
+		Right:   ebiten.IsKeyPressed(ebiten.KeyD),
+		Fire:    ebiten.IsKeyPressed(ebiten.KeyF) || autoFire,
+		Special: inpututil.IsKeyJustPressed(ebiten.KeyW),
+	}
+}
+
+func gamepadControls() sim.Controls {
+	gamepads := ebiten.AppendGamepadIDs(nil)
+	if len(gamepads) == 0 || !ebiten.IsStandardGamepadLayoutAvailable(gamepads[0]) {
+		return sim.Controls{}
+	}
+	pad := gamepads[0]
+	stick := ebiten.StandardGamepadAxisValue(pad, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	return sim.Controls{
+		Left:    stick < -gamepadDeadZone || ebiten.IsStandardGamepadButtonPressed(pad, ebiten.StandardGamepadButtonLeftLeft),
+		Right:   stick > gamepadDeadZone || ebiten.IsStandardGamepadButtonPressed(pad, ebiten.StandardGamepadButtonLeftRight),
+		Fire:    ebiten.IsStandardGamepadButtonPressed(pad, ebiten.StandardGamepadButtonRightBottom),
+		Special: inpututil.IsStandardGamepadButtonJustPressed(pad, ebiten.StandardGamepadButtonRightTop),
+	}
+}
+
+// drawPilot tells the players apart: player two is drawn in a cooler tone,
+// and a downed player is faded with their revive progress above them.
+func (g *Game) drawPilot(screen *ebiten.Image, index int) {
+	p := g.world.Pilots[index]
+	options := &ebiten.DrawImageOptions{}
+	options.GeoM.Scale(sim.PlayerScale, sim.PlayerScale)
+	options.GeoM.Translate(p.Position.X, p.Position.Y)
+	if index == sim.PlayerTwo {
+		options.ColorScale.Scale(0.65, 0.9, 1.2, 1)
+	}
+	if p.Down {
+		options.ColorScale.ScaleAlpha(0.4)
+	}
+	screen.DrawImage(g.playerImage, options)
+	if !p.Down || p.ReviveTicks == 0 {
+		return
+	}
+	width := g.sizes.PilotWidth()
+	progress := float64(p.ReviveTicks) / sim.ReviveTime
+	ebitenutil.DrawRect(screen, p.Position.X, p.Position.Y-8, width, 5, color.RGBA{R: 45, G: 45, B: 60, A: 255})
+	ebitenutil.DrawRect(screen, p.Position.X, p.Position.Y-8, width*progress, 5, color.RGBA{R: 120, G: 240, B: 120, A: 255})
+}
+
+// pilotLabel prefixes a HUD row with the player number in co-op.
+func (g *Game) pilotLabel(index int, message string) string {
+	if !g.world.Coop {
+		return message
+	}
+	return g.message("hud.pilot", index+1, message)
+}
+
+// drawPilotHUD draws a player's KIEE gauge, combo and, with split scores,
+// their share of the score.
+func (g *Game) drawPilotHUD(stack *hudStack, index int) {
+	p := g.world.Pilots[index]
+	colors := g.palette()
+	if g.world.Coop && (g.settings.Coop.SplitScore || g.versus()) {
+		stack.text(g.pilotLabel(index, g.message("hud.points", p.Points)), fontSmall, color.White)
+	}
+	cost := g.world.SpecialCost()
+	charge := kieeCharge(p.MissCount, cost)
+	fillColor := colors.kieeFill
+	if charge >= cost {
+		fillColor = colors.kieeFull
+	}
+	stack.gauge(hudGauge{
+		label:      g.pilotLabel(index, g.message("hud.kiee")),
+		value:      fmt.Sprintf("%d/%d", charge, cost),
+		width:      hudKIEEGaugeSize,
+		fillWidth:  func(inside int) float64 { return kieeGaugeFillWidth(charge, cost, inside) },
+		background: colors.kieeBackground,
+		fill:       fillColor,
+	}, fontSmall, color.White)
+	if p.Down {
+		stack.text(g.pilotLabel(index, g.message("hud.down")), fontSmall, color.RGBA{R: 255, G: 90, B: 70, A: 255})
+		return
+	}
+	stack.text(g.pilotLabel(index, g.message("hud.combo", p.Combo, p.ComboMultiplier())), fontSmall, color.White)
+}
+
+// partnerHUDStack is the top-right stack for player two, below the mute
+// button.
+func (g *Game) partnerHUDStack(screen *ebiten.Image) *hudStack {
+	stack := g.newHUDStack(screen, anchorTopRight)
+	stack.used = float64(muteButtonRect().Max.Y)
+	return stack
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const dailySaveName = "daily"
+
+// dailyModifiers rotate through the daily challenge.
+var dailyModifiers = []sim.Modifier{sim.ModifierFastUFOs, sim.ModifierNoPowerUps, sim.ModifierCheapKIEE}
+
+// dailyChallenge is the challenge of the UTC day a daily run started on.
+type dailyChallenge struct {
+	date     string
+	modifier sim.Modifier
+	ranked   bool
+}
+
+func dailyDate(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+// dailySeed gives everyone the same enemy pattern on the same UTC date.
+func dailySeed(date string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("mygame daily " + date))
+	return int64(hash.Sum64())
+}
+
+// dailyModifierFor rotates the modifiers one per day.
+func dailyModifierFor(date string) sim.Modifier {
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return dailyModifiers[0]
+	}
+	days := int(day.Unix() / (24 * 60 * 60))
+	return dailyModifiers[days%len(dailyModifiers)]
+}
+
+// dailyRecord remembers whether today's ranked attempt was used and its best
+// score. Records from an earlier date count as empty.
+type dailyRecord struct {
+	Date      string `json:"date"`
+	Attempted bool   `json:"attempted"`
+	Best      int    `json:"best"`
+}
+
+type dailyStore interface {
+	Load(date string) (dailyRecord, error)
+	Save(record dailyRecord) error
+}
+
+type savedDailyStore struct{}
+
+func newDailyStore() dailyStore {
+	return savedDailyStore{}
+}
+
+func (savedDailyStore) Load(date string) (dailyRecord, error) {
+	data, err := readSaveData(dailySaveName)
+	if err != nil || data == nil {
+		return dailyRecord{Date: date}, err
+	}
+	var record dailyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return dailyRecord{Date: date}, fmt.Errorf("parse daily record: %w", err)
+	}
+	if record.Date != date {
+		return dailyRecord{Date: date}, nil
+	}
+	return record, nil
+}
+
+func (savedDailyStore) Save(record dailyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return writeSaveData(dailySaveName, data)
+}
+
+// dailyHighScoreStore keeps the day's best score in the daily record, so the
+// usual high score code tracks the daily challenge too.
+type dailyHighScoreStore struct {
+	store dailyStore
+	date  string
+}
+
+func (store dailyHighScoreStore) Load() (int, error) {
+	record, err := store.store.Load(store.date)
+	return record.Best, err
+}
+
+func (store dailyHighScoreStore) Save(score int) error {
+	record, err := store.store.Load(store.date)
+	if err != nil {
+		return err
+	}
+	record.Best = max(0, score)
+	return store.store.Save(record)
+}
+
+func (g *Game) dailyRecord(date string) dailyRecord {
+	if g.dailyStore == nil {
+		return dailyRecord{Date: date}
+	}
+	record, err := g.dailyStore.Load(date)
+	if err != nil {
+		log.Printf("load daily record: %v", err)
+	}
+	return record
+}
+
+// prepareDaily loads the challenge and best score of the UTC day for the
+// title screen.
+func (g *Game) prepareDaily(now time.Time) {
+	date := dailyDate(now)
+	g.daily = dailyChallenge{date: date, modifier: dailyModifierFor(date)}
+	if g.dailyStore == nil {
+		return
+	}
+	g.highScoreStore = dailyHighScoreStore{store: g.dailyStore, date: date}
+	record := g.dailyRecord(date)
+	g.highScore = record.Best
+	g.daily.ranked = !record.Attempted
+}
+
+// startDaily spends the day's ranked attempt
+// if it is still free. Later attempts that day are practice and do not count.
+func (g *Game) startDaily(now time.Time) {
+	g.prepareDaily(now)
+	if !g.daily.ranked {
+		return
+	}
+	record := g.dailyRecord(g.daily.date)
+	record.Attempted = true
+	if err := g.dailyStore.Save(record); err != nil {
+		log.Printf("save daily record: %v", err)
+	}
+}
+
+// dailyUnranked reports a daily run after the day's ranked attempt was used.
+func (g *Game) dailyUnranked() bool {
+	return g.mode == modeDaily && !g.daily.ranked
+}
+
+func (g *Game) dailyTitle() string {
+	status := g.message("daily.open")
+	if !g.daily.ranked {
+		status = g.message("daily.used")
+	}
+	return g.message("daily.title", g.message("modifier."+string(g.daily.modifier)), status)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+type fakeDailyStore struct {
+	record dailyRecord
+	saves  int
+}
+
+func (store *fakeDailyStore) Load(date string) (dailyRecord, error) {
+	if store.record.Date != date {
+		return dailyRecord{Date: date}, nil
+	}
+	return store.record, nil
+}
+
+func (store *fakeDailyStore) Save(record dailyRecord) error {
+	store.record = record
+	store.saves++
+	return nil
+}
+
+func TestDailySeedAndModifierFollowTheUTCDate(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	morning := time.Date(2026, 10, 20, 8, 0, 0, 0, tokyo)
+	if date := dailyDate(morning); date != "2026-10-19" {
+		t.Fatalf("date = %q, want the UTC date 2026-10-19", date)
+	}
+	if dailySeed("2026-10-19") != dailySeed("2026-10-19") || dailySeed("2026-10-19") == dailySeed("2026-10-20") {
+		t.Fatal("daily seeds should match on the same date and differ between dates")
+	}
+	seen := map[sim.Modifier]bool{}
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	for offset := range len(dailyModifiers) {
+		seen[dailyModifierFor(dailyDate(day.AddDate(0, 0, offset)))] = true
+	}
+	if len(seen) != len(dailyModifiers) {
+		t.Fatalf("modifiers over %d days = %v, want each one once", len(dailyModifiers), seen)
+	}
+}
+
+func TestDailyAllowsOneRankedAttempt(t *testing.T) {
+	store := &fakeDailyStore{}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	g := &Game{mode: modeDaily, dailyStore: store, world: &sim.World{}}
+	g.startDaily(now)
+	if !g.daily.ranked || !store.record.Attempted {
+		t.Fatalf("daily = %+v record = %+v, want the first attempt ranked", g.daily, store.record)
+	}
+	g.world.Score = 30
+	g.updateHighScore()
+	if store.record.Best != 30 || g.highScore != 30 {
+		t.Fatalf("best = %d high score = %d, want 30", store.record.Best, g.highScore)
+	}
+
+	g.world.Score = 0
+	g.startDaily(now)
+	if g.daily.ranked || !g.dailyUnranked() {
+		t.Fatal("second attempt on the same day was ranked")
+	}
+	g.world.Score = 50
+	g.updateHighScore()
+	if store.record.Best != 30 {
+		t.Fatalf("best = %d, want the practice run ignored", store.record.Best)
+	}
+
+	g.startDaily(now.AddDate(0, 0, 1))
+	if !g.daily.ranked || g.highScore != 0 {
+		t.Fatalf("daily = %+v high score = %d, want a fresh ranked attempt the next day", g.daily, g.highScore)
+	}
+}
+
+func TestDailyRunsUseTheDailyRules(t *testing.T) {
+	g := &Game{settings: settings{Mode: modeDaily, Difficulty: sim.DifficultyLunatic, AdaptiveDifficulty: true}}
+	g.startMode()
+	if g.world.Modifier != g.daily.modifier || g.world.Seed != dailySeed(g.daily.date) {
+		t.Fatalf("modifier = %q seed = %d, want today's challenge", g.world.Modifier, g.world.Seed)
+	}
+	if g.world.Difficulty != sim.DifficultyNormal || g.world.Adaptive {
+		t.Fatal("daily run used the chosen difficulty or the director")
+	}
+	g.mode = modeWaves
+	if world := g.newWorld(1, false); world.Modifier != "" || world.Difficulty != sim.DifficultyLunatic || !world.Adaptive {
+		t.Fatalf("world = %+v, want the modifier left out of other modes", world.Config)
+	}
+}
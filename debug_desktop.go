@@ -7,3 +7,9 @@ import "os"
 func debugModeEnabled() bool {
 	return os.Getenv("MYGAME_DEBUG") == "1"
 }
+
+// synthAudioForced replaces every sound file with the built-in synthesizer,
+// which is handy for prototyping effects in code.
+func synthAudioForced() bool {
+	return os.Getenv("MYGAME_SYNTH") == "1"
+}
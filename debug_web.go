@@ -5,6 +5,16 @@ package main
 import "syscall/js"
 
 func debugModeEnabled() bool {
+	return queryParam("debug") == "1"
+}
+
+// synthAudioForced replaces every sound file with the built-in synthesizer,
+// which is handy for prototyping effects in code.
+func synthAudioForced() bool {
+	return queryParam("synth") == "1"
+}
+
+func queryParam(name string) string {
 	params := js.Global().Get("URLSearchParams").New(js.Global().Get("location").Get("search"))
-	return params.Call("get", "debug").String() == "1"
+	return params.Call("get", name).String()
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"image"
+	"log"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+// difficultyName is the difficulty the next run will play, for the title.
+func (g *Game) difficultyName() string {
+	if g.settings.Mode == modeDaily {
+		return g.message("difficulty.fixed", g.message("difficulty."+difficultyKey(sim.DifficultyNormal)))
+	}
+	return g.message("difficulty." + difficultyKey(g.settings.Difficulty))
+}
+
+// worldDifficultyName is the difficulty the world on screen plays, for the
+// HUD. The daily challenge, online games and the demo play Normal whatever
+// the settings say.
+func (g *Game) worldDifficultyName() string {
+	name := g.message("difficulty." + difficultyKey(g.world.Difficulty))
+	if g.mode == modeDaily && g.state != stateAttract {
+		return g.message("difficulty.fixed", name)
+	}
+	return name
+}
+
+func difficultyKey(level sim.Difficulty) string {
+	if level == sim.DifficultyNormal {
+		return "normal"
+	}
+	return string(level)
+}
+
+// highScoreVariant names the record for a mode, difficulty and team size.
+// Waves on Normal keep the original high score file so records from before
+// modes and difficulty levels carry over. Co-op teams score together, so
+// they get records of their own.
+func highScoreVariant(mode gameMode, level sim.Difficulty, coop bool) string {
+	var variant string
+	switch {
+	case mode == modeWaves:
+		variant = string(level)
+	case level == sim.DifficultyNormal:
+		variant = string(mode)
+	default:
+		variant = string(mode) + "-" + string(level)
+	}
+	if !coop {
+		return variant
+	}
+	if variant == "" {
+		return "coop"
+	}
+	return variant + "-coop"
+}
+
+func (g *Game) cycleDifficulty(delta int) {
+	if g.settings.Mode == modeDaily {
+		return
+	}
+	current := 0
+	for index, level := range sim.Difficulties {
+		if level == g.settings.Difficulty {
+			current = index
+		}
+	}
+	g.settings.Difficulty = sim.Difficulties[min(len(sim.Difficulties)-1, max(0, current+delta))]
+	g.saveSettings()
+	g.loadHighScore()
+}
+
+// loadHighScore switches to the high score for the selected mode, difficulty
+// and co-op setting.
+func (g *Game) loadHighScore() {
+	if g.settings.Mode == modeDaily {
+		g.prepareDaily(time.Now())
+		return
+	}
+	g.highScoreStore = newHighScoreStore(highScoreVariant(g.settings.Mode, g.settings.Difficulty, g.settings.Coop.Enabled))
+	highScore, err := g.highScoreStore.Load()
+	if err != nil {
+		log.Printf("load high score: %v", err)
+	}
+	g.highScore = max(0, highScore)
+}
+
+// difficultyTapped reports a click or tap on the title's difficulty row, which
+// cycles the difficulty instead of starting a run.
+func (g *Game) difficultyTapped() bool {
+	row := difficultyRowRect()
+	for _, position := range g.justPressedPointerPositions() {
+		if position.In(row) {
+			return true
+		}
+	}
+	return false
+}
+
+func difficultyRowRect() image.Rectangle {
+	return image.Rect(screenWidth/2-140, titleDifficultyY-26, screenWidth/2+140, titleDifficultyY+8)
+}
+
+// adaptiveDifficultyMenuItem turns on the director, which nudges spawn rates
+// and speeds to how well the player is doing.
+func (g *Game) adaptiveDifficultyMenuItem() menuItem {
+	return g.toggleItem("settings.adaptive", &g.settings.AdaptiveDifficulty)
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestDecodeSettingsResetsUnknownDifficulty(t *testing.T) {
+	decoded, err := decodeSettings([]byte(`{"difficulty":"impossible"}`))
+	if err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if decoded.Difficulty != sim.DifficultyNormal {
+		t.Fatalf("difficulty = %q, want normal", decoded.Difficulty)
+	}
+	decoded, err = decodeSettings([]byte(`{"difficulty":"hard"}`))
+	if err != nil || decoded.Difficulty != sim.DifficultyHard {
+		t.Fatalf("difficulty = %q, %v, want hard", decoded.Difficulty, err)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// scaleMode is how the 640x480 game screen is enlarged to fill the window.
+type scaleMode string
+
+const (
+	// scaleFit fills as much of the window as possible.
+	scaleFit scaleMode = "fit"
+	// scaleInteger only uses whole multiples so every pixel is the same size.
+	scaleInteger scaleMode = "integer"
+)
+
+// displaySettings are the window and scaling preferences. Window is only
+// used by the desktop build, which restores the last size and position.
+type displaySettings struct {
+	Scale      scaleMode      `json:"scale"`
+	Fullscreen bool           `json:"fullscreen"`
+	Window     windowGeometry `json:"window"`
+}
+
+// windowGeometry is the windowed-mode size and position in device-independent
+// pixels. A zero size means the default window.
+type windowGeometry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func defaultDisplaySettings() displaySettings {
+	return displaySettings{Scale: scaleFit}
+}
+
+func (display displaySettings) clamped() displaySettings {
+	if display.Scale != scaleInteger {
+		display.Scale = scaleFit
+	}
+	if display.Window.Width < screenWidth/2 || display.Window.Height < screenHeight/2 {
+		display.Window = windowGeometry{}
+	}
+	return display
+}
+
+// viewport is where the game screen is drawn inside the window's framebuffer.
+type viewport struct {
+	x, y  float64
+	scale float64
+}
+
+// computeViewport centers the game screen in an outer framebuffer of the
+// given size, leaving black bars on the sides that do not fit.
+func computeViewport(outerWidth, outerHeight float64, mode scaleMode) viewport {
+	scale := min(outerWidth/screenWidth, outerHeight/screenHeight)
+	if mode == scaleInteger && scale >= 1 {
+		scale = math.Floor(scale)
+	}
+	return viewport{
+		x:     math.Floor((outerWidth - screenWidth*scale) / 2),
+		y:     math.Floor((outerHeight - screenHeight*scale) / 2),
+		scale: scale,
+	}
+}
+
+// toGame converts a framebuffer position to game screen coordinates. The zero
+// viewport, before the first layout, leaves positions unchanged.
+func (view viewport) toGame(x, y int) image.Point {
+	if view.scale <= 0 {
+		return image.Pt(x, y)
+	}
+	return image.Pt(
+		int(math.Floor((float64(x)-view.x)/view.scale)),
+		int(math.Floor((float64(y)-view.y)/view.scale)),
+	)
+}
+
+// LayoutF renders at the device's native resolution so the game screen is
+// scaled once, with pixel-preserving filtering, instead of being stretched
+// again by the browser or OS.
+func (g *Game) LayoutF(outsideWidth, outsideHeight float64) (float64, float64) {
+	deviceScale := ebiten.Monitor().DeviceScaleFactor()
+	width := math.Ceil(outsideWidth * deviceScale)
+	height := math.Ceil(outsideHeight * deviceScale)
+	g.viewport = computeViewport(width, height, g.settings.Display.Scale)
+	return width, height
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	width, height := g.LayoutF(float64(outsideWidth), float64(outsideHeight))
+	return int(width), int(height)
+}
+
+// Draw renders the game at 640x480 and then scales it into the window.
+func (g *Game) Draw(screen *ebiten.Image) {
+	if g.canvas == nil {
+		g.canvas = ebiten.NewImage(screenWidth, screenHeight)
+	}
+	g.canvas.Clear()
+	g.drawScene(g.canvas)
+
+	screen.Fill(color.Black)
+	options := &ebiten.DrawImageOptions{Filter: ebiten.FilterPixelated}
+	options.GeoM.Scale(g.viewport.scale, g.viewport.scale)
+	options.GeoM.Translate(g.viewport.x, g.viewport.y)
+	screen.DrawImage(g.canvas, options)
+}
+
+func (g *Game) cursorPosition() image.Point {
+	return g.viewport.toGame(ebiten.CursorPosition())
+}
+
+func (g *Game) touchPosition(id ebiten.TouchID) image.Point {
+	return g.viewport.toGame(ebiten.TouchPosition(id))
+}
+
+func (g *Game) previousTouchPosition(id ebiten.TouchID) image.Point {
+	return g.viewport.toGame(inpututil.TouchPositionInPreviousTick(id))
+}
+
+// justPressedPointerPositions returns where the mouse was clicked or the
+// screen was touched this tick, in game screen coordinates.
+func (g *Game) justPressedPointerPositions() []image.Point {
+	var positions []image.Point
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		positions = append(positions, g.cursorPosition())
+	}
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		positions = append(positions, g.touchPosition(id))
+	}
+	return positions
+}
+
+func altPressed() bool {
+	return ebiten.IsKeyPressed(ebiten.KeyAltLeft) || ebiten.IsKeyPressed(ebiten.KeyAltRight)
+}
+
+func fullscreenShortcutPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyF11) || (altPressed() && inpututil.IsKeyJustPressed(ebiten.KeyEnter))
+}
+
+// updateDisplay handles the fullscreen shortcuts and remembers the window.
+func (g *Game) updateDisplay() {
+	if fullscreenShortcutPressed() {
+		g.toggleFullscreen()
+	}
+	g.trackWindow()
+}
+
+func (g *Game) toggleFullscreen() {
+	g.settings.Display.Fullscreen = !g.settings.Display.Fullscreen
+	ebiten.SetFullscreen(g.settings.Display.Fullscreen)
+	g.saveSettings()
+}
+
+func (g *Game) cycleScaleMode(int) {
+	if g.settings.Display.Scale == scaleInteger {
+		g.settings.Display.Scale = scaleFit
+	} else {
+		g.settings.Display.Scale = scaleInteger
+	}
+	g.saveSettings()
+}
+
+func (g *Game) scaleModeLabel() string {
+	if g.settings.Display.Scale == scaleInteger {
+		return g.message("settings.scaleInteger")
+	}
+	return g.message("settings.scaleFit")
+}
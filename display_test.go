@@ -0,0 +1,52 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestComputeViewportLetterboxes(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height float64
+		mode          scaleMode
+		want          viewport
+	}{
+		{"exact 2x", 1280, 960, scaleFit, viewport{0, 0, 2}},
+		{"wide fit", 1920, 1080, scaleFit, viewport{240, 0, 2.25}},
+		{"wide integer", 1920, 1080, scaleInteger, viewport{320, 60, 2}},
+		{"tall fit", 640, 1000, scaleFit, viewport{0, 260, 1}},
+		{"smaller than the game", 320, 240, scaleInteger, viewport{0, 0, 0.5}},
+	}
+	for _, test := range tests {
+		if got := computeViewport(test.width, test.height, test.mode); got != test.want {
+			t.Errorf("%s: viewport = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestViewportMapsPointersToGameCoordinates(t *testing.T) {
+	view := computeViewport(1920, 1080, scaleInteger)
+	if got := view.toGame(320, 60); got != image.Pt(0, 0) {
+		t.Fatalf("top-left corner = %v, want (0,0)", got)
+	}
+	if got := view.toGame(320+2*100, 60+2*50+1); got != image.Pt(100, 50) {
+		t.Fatalf("inside point = %v, want (100,50)", got)
+	}
+	if got := view.toGame(10, 10); got.In(image.Rect(0, 0, screenWidth, screenHeight)) {
+		t.Fatalf("letterbox point = %v, want it outside the game screen", got)
+	}
+	if got := (viewport{}).toGame(12, 34); got != image.Pt(12, 34) {
+		t.Fatalf("zero viewport moved the point to %v", got)
+	}
+}
+
+func TestDecodeSettingsDropsUnusableDisplayValues(t *testing.T) {
+	decoded, err := decodeSettings([]byte(`{"display":{"scale":"stretch","window":{"x":5,"y":5,"width":10,"height":10}}}`))
+	if err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if decoded.Display != defaultDisplaySettings() {
+		t.Fatalf("display = %+v, want defaults", decoded.Display)
+	}
+}
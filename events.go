@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+// eventBus passes the simulation's events to the systems that react to them:
+// audio, effects, achievements, stats, telemetry and logging. It calls every
+// subscriber in the order they subscribed, right when an
+// event is emitted, so the order of side effects stays the same every run.
+type eventBus struct {
+	subscribers []func(event sim.Event)
+}
+
+func (bus *eventBus) subscribe(subscriber func(event sim.Event)) {
+	bus.subscribers = append(bus.subscribers, subscriber)
+}
+
+func (bus *eventBus) emit(event sim.Event) {
+	for _, subscriber := range bus.subscribers {
+		subscriber(event)
+	}
+}
+
+// subscribeSystems connects the systems that react to gameplay events.
+func (g *Game) subscribeSystems() {
+	g.events.subscribe(g.soundEvent)
+	g.events.subscribe(g.effectEvent)
+	g.events.subscribe(g.achievementEvent)
+	g.events.subscribe(g.statsEvent)
+	g.events.subscribe(g.telemetryEvent)
+	g.events.subscribe(g.logEvent)
+}
+
+func (g *Game) emit(event sim.Event) {
+	g.events.emit(event)
+}
+
+// logEvent writes the gameplay log, with details only in debug mode.
+func (g *Game) logEvent(event sim.Event) {
+	switch event := event.(type) {
+	case sim.PlayerDied:
+		world := g.world
+		p := world.Pilots[event.Player]
+		if event.Down {
+			log.Printf("co-op: player %d is down (wave=%d score=%d)", event.Player+1, world.Wave, world.Score)
+			return
+		}
+		log.Printf("game over: player collided with enemy (wave=%d score=%d combo=%d player=(%.1f,%.1f) enemy=(%.1f,%.1f))", world.Wave, world.Score, p.Combo, p.Position.X, p.Position.Y, event.Enemy.X, event.Enemy.Y)
+	case sim.PlayerRevived:
+		log.Printf("co-op: player %d was revived (wave=%d)", event.Player+1, g.world.Wave)
+	case sim.TimeUp:
+		log.Printf("game over: time up (wave=%d score=%d combo=%d)", g.world.Wave, g.world.Score, g.world.Pilots[sim.PlayerOne].Combo)
+	case sim.PowerUpCollected:
+		if g.debug {
+			log.Printf("debug: power-up collected by player %d (%d ticks)", event.Player+1, tuning.PowerUpDuration)
+		}
+	case sim.BossDefeated:
+		if g.debug {
+			log.Printf("debug: boss defeated by player %d (wave=%d score=%d)", event.Shooter+1, g.world.Wave, g.world.Score)
+		}
+	}
+}
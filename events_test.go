@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestEventBusCallsSubscribersInOrder(t *testing.T) {
+	var bus eventBus
+	var calls []string
+	bus.subscribe(func(sim.Event) { calls = append(calls, "first") })
+	bus.subscribe(func(sim.Event) { calls = append(calls, "second") })
+	bus.emit(sim.WaveStarted{Wave: 1})
+	if !reflect.DeepEqual(calls, []string{"first", "second"}) {
+		t.Fatalf("calls = %v, want subscription order", calls)
+	}
+}
+
+func TestSubscribedSystemsFollowEvents(t *testing.T) {
+	g := &Game{world: &sim.World{Wave: 4}}
+	g.subscribeSystems()
+	g.emit(sim.ShotFired{Count: 3})
+	g.emit(sim.EbiHit{})
+	g.emit(sim.PlayerDied{})
+	if g.stats.Shots != 3 || g.stats.Ebis != 1 || g.stats.Deaths["4"] != 1 || g.progress.ebisShot != 1 {
+		t.Fatalf("stats = %+v progress = %+v after shot, ebi and death events", g.stats, g.progress)
+	}
+}
+
+func TestOpeningBossRushWaveReachesTheBus(t *testing.T) {
+	g := &Game{mode: modeBossRush}
+	var started []int
+	g.events.subscribe(func(event sim.Event) {
+		if event, ok := event.(sim.WaveStarted); ok {
+			started = append(started, event.Wave)
+		}
+	})
+	g.newWorld(1, false)
+	if !reflect.DeepEqual(started, []int{sim.BossWaveCycle}) {
+		t.Fatalf("waves started = %v, want the opening boss wave", started)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	maxGhostTicks    = 10 * 60 * 60 // Runs longer than 10 minutes keep only their start.
+	ghostSampleTicks = 6            // Ticks between saved positions; drawing fills in the rest.
+	ghostAlpha       = 0.35
+)
+
+// ghostRun is the personal-best run of a mode and difficulty. To keep it
+// small enough for the browser's storage, it saves player one's position
+// every ghostSampleTicks ticks and the score only on the ticks it changed.
+type ghostRun struct {
+	Score      int   `json:"score"`
+	Ticks      int   `json:"ticks"`
+	Positions  []int `json:"positions"`
+	ScoreTicks []int `json:"scoreTicks"`
+	Scores     []int `json:"scores"`
+}
+
+// check rejects a ghost whose parts do not line up, such as one saved in the
+// old every-tick format.
+func (run *ghostRun) check() error {
+	if run.Ticks < 0 || len(run.Positions) != (run.Ticks+ghostSampleTicks-1)/ghostSampleTicks {
+		return fmt.Errorf("%d positions for %d ticks", len(run.Positions), run.Ticks)
+	}
+	if len(run.ScoreTicks) != len(run.Scores) {
+		return fmt.Errorf("%d score ticks for %d scores", len(run.ScoreTicks), len(run.Scores))
+	}
+	if !sort.IntsAreSorted(run.ScoreTicks) {
+		return fmt.Errorf("score ticks out of order")
+	}
+	return nil
+}
+
+// scoreAt is the score the ghost had after tick.
+func (run *ghostRun) scoreAt(tick int) int {
+	index := sort.SearchInts(run.ScoreTicks, tick+1)
+	if index == 0 {
+		return 0
+	}
+	return run.Scores[index-1]
+}
+
+// positionAt is player one's position at tick, between the saved samples.
+func (run *ghostRun) positionAt(tick int) float64 {
+	index := tick / ghostSampleTicks
+	position := float64(run.Positions[index])
+	if index+1 >= len(run.Positions) {
+		return position
+	}
+	progress := float64(tick%ghostSampleTicks) / ghostSampleTicks
+	return position + (float64(run.Positions[index+1])-position)*progress
+}
+
+type ghostStore interface {
+	Load(variant string) (*ghostRun, error)
+	Save(variant string, run *ghostRun) error
+}
+
+type savedGhostStore struct{}
+
+func newGhostStore() ghostStore {
+	return savedGhostStore{}
+}
+
+// ghostSaveName keeps one ghost per high score, named like the high score
+// files.
+func ghostSaveName(variant string) string {
+	if variant == "" {
+		return "ghost"
+	}
+	return "ghost-" + variant
+}
+
+func (savedGhostStore) Load(variant string) (*ghostRun, error) {
+	data, err := readSaveData(ghostSaveName(variant))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var run ghostRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("parse ghost: %w", err)
+	}
+	if err := run.check(); err != nil {
+		return nil, fmt.Errorf("parse ghost: %w", err)
+	}
+	return &run, nil
+}
+
+func (savedGhostStore) Save(variant string, run *ghostRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return writeSaveData(ghostSaveName(variant), data)
+}
+
+func (g *Game) ghostVariant() string {
+	return highScoreVariant(g.mode, g.world.Difficulty, g.world.Coop)
+}
+
+// startGhost begins recording a run that could become the personal best and
+// loads the ghost to race against. The daily challenge changes every day, so
+// it has no ghost.
+func (g *Game) startGhost() {
+	g.ghost = nil
+	g.ghostRecording = nil
+	if g.ghostStore == nil || !g.ranked() || g.mode == modeDaily {
+		return
+	}
+	g.ghostRecording = &ghostRun{}
+	ghost, err := g.ghostStore.Load(g.ghostVariant())
+	if err != nil {
+		log.Printf("load ghost: %v", err)
+	}
+	g.ghost = ghost
+}
+
+// recordGhost stores the tick that just ran.
+func (g *Game) recordGhost() {
+	recording := g.ghostRecording
+	if recording == nil || recording.Ticks >= maxGhostTicks {
+		return
+	}
+	tick := recording.Ticks
+	if tick%ghostSampleTicks == 0 {
+		recording.Positions = append(recording.Positions, int(g.world.Pilots[sim.PlayerOne].Position.X))
+	}
+	if recording.scoreAt(tick) != g.world.Score {
+		recording.ScoreTicks = append(recording.ScoreTicks, tick)
+		recording.Scores = append(recording.Scores, g.world.Score)
+	}
+	recording.Ticks++
+}
+
+// finishGhost saves the run that just ended if it beat the ghost.
+func (g *Game) finishGhost() {
+	recording := g.ghostRecording
+	g.ghostRecording = nil
+	if recording == nil || recording.Ticks == 0 {
+		return
+	}
+	recording.Score = g.world.Score
+	if g.ghost != nil && recording.Score <= g.ghost.Score {
+		return
+	}
+	if err := g.ghostStore.Save(g.ghostVariant(), recording); err != nil {
+		log.Printf("save ghost: %v", err)
+		return
+	}
+	if g.debug {
+		log.Printf("debug: saved a %d tick ghost with score %d", recording.Ticks, recording.Score)
+	}
+}
+
+// ghostTick is the index of the ghost frame that matches the live game.
+func (g *Game) ghostTick() int {
+	if g.ghostRecording == nil {
+		return 0
+	}
+	return g.ghostRecording.Ticks
+}
+
+// ghostScoreDelta compares the score with the ghost's at the same moment.
+// After the ghost's run ended, its final score counts.
+func (g *Game) ghostScoreDelta() (int, bool) {
+	if g.ghost == nil || !g.settings.Ghost || g.ghost.Ticks == 0 {
+		return 0, false
+	}
+	return g.world.Score - g.ghost.scoreAt(g.ghostTick()), true
+}
+
+// drawGhost draws player one's past self, faded, until the ghost's run ended.
+func (g *Game) drawGhost(screen *ebiten.Image) {
+	if g.ghost == nil || !g.settings.Ghost || g.ghostTick() >= g.ghost.Ticks {
+		return
+	}
+	options := &ebiten.DrawImageOptions{}
+	options.GeoM.Scale(sim.PlayerScale, sim.PlayerScale)
+	options.GeoM.Translate(g.ghost.positionAt(g.ghostTick()), g.world.Pilots[sim.PlayerOne].Position.Y)
+	options.ColorScale.Scale(0.6, 0.8, 1, 1)
+	options.ColorScale.ScaleAlpha(ghostAlpha)
+	screen.DrawImage(g.playerImage, options)
+}
+
+func (g *Game) drawGhostHUD(stack *hudStack) {
+	delta, ok := g.ghostScoreDelta()
+	if !ok {
+		return
+	}
+	clr := color.RGBA{R: 120, G: 240, B: 120, A: 255}
+	if delta < 0 {
+		clr = color.RGBA{R: 255, G: 90, B: 70, A: 255}
+	}
+	stack.text(g.message("hud.ghost", delta), fontSmall, clr)
+}
+
+func (g *Game) ghostMenuItem() menuItem {
+	return g.toggleItem("settings.ghost", &g.settings.Ghost)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+type fakeGhostStore struct {
+	runs  map[string]*ghostRun
+	saves int
+}
+
+func (store *fakeGhostStore) Load(variant string) (*ghostRun, error) {
+	return store.runs[variant], nil
+}
+
+func (store *fakeGhostStore) Save(variant string, run *ghostRun) error {
+	store.runs[variant] = run
+	store.saves++
+	return nil
+}
+
+func TestGhostIsSavedOnlyWhenBeaten(t *testing.T) {
+	store := &fakeGhostStore{runs: map[string]*ghostRun{"hard": {Score: 5, Ticks: 1, Positions: []int{0}, ScoreTicks: []int{0}, Scores: []int{5}}}}
+	g := &Game{ghostStore: store, world: &sim.World{Config: sim.Config{Difficulty: sim.DifficultyHard}}}
+	g.startGhost()
+	g.world.Pilots[sim.PlayerOne].Position.X = 120
+	g.world.Score = 5
+	g.recordGhost()
+	g.finishGhost()
+	if store.saves != 0 {
+		t.Fatal("a run that only tied the ghost replaced it")
+	}
+
+	g.startGhost()
+	for range ghostSampleTicks {
+		g.recordGhost()
+	}
+	g.world.Pilots[sim.PlayerOne].Position.X = 180
+	g.world.Score = 9
+	g.recordGhost()
+	g.finishGhost()
+	saved := store.runs["hard"]
+	if store.saves != 1 || saved.Score != 9 || saved.Ticks != ghostSampleTicks+1 || saved.check() != nil {
+		t.Fatalf("saved %+v, want the better run", saved)
+	}
+	if len(saved.Positions) != 2 || saved.Positions[0] != 120 || saved.Positions[1] != 180 {
+		t.Fatalf("positions = %v, want one sample every %d ticks", saved.Positions, ghostSampleTicks)
+	}
+	if len(saved.Scores) != 2 || saved.ScoreTicks[1] != ghostSampleTicks || saved.Scores[0] != 5 || saved.Scores[1] != 9 {
+		t.Fatalf("score ticks = %v scores = %v, want only the two changes", saved.ScoreTicks, saved.Scores)
+	}
+}
+
+func TestGhostPositionsAreFilledInBetweenSamples(t *testing.T) {
+	run := &ghostRun{Ticks: ghostSampleTicks + 1, Positions: []int{0, 60}}
+	if position := run.positionAt(ghostSampleTicks / 2); position != 30 {
+		t.Fatalf("position = %v, want 30 halfway between the samples", position)
+	}
+	if position := run.positionAt(ghostSampleTicks); position != 60 {
+		t.Fatalf("position = %v, want the last sample", position)
+	}
+}
+
+func TestUnrankedRunsHaveNoGhost(t *testing.T) {
+	store := &fakeGhostStore{runs: map[string]*ghostRun{}}
+	for _, g := range []*Game{{ghostStore: store, assisted: true}, {ghostStore: store, mode: modePractice}, {ghostStore: store, mode: modeDaily}} {
+		g.startGhost()
+		if g.ghostRecording != nil {
+			t.Fatalf("mode %q assisted=%v recorded a ghost", g.mode, g.assisted)
+		}
+	}
+}
+
+func TestGhostScoreDeltaFollowsTheGhostsTimeline(t *testing.T) {
+	g := &Game{world: &sim.World{}, ghost: &ghostRun{Score: 6, Ticks: 3, Positions: []int{0}, ScoreTicks: []int{1, 2}, Scores: []int{2, 6}}, ghostRecording: &ghostRun{}}
+	if _, ok := g.ghostScoreDelta(); ok {
+		t.Fatal("delta shown with the ghost turned off")
+	}
+	g.settings.Ghost = true
+	g.ghostRecording.Ticks = 1
+	g.world.Score = 1
+	if delta, _ := g.ghostScoreDelta(); delta != -1 {
+		t.Fatalf("delta = %d, want -1 against the ghost's 2 points", delta)
+	}
+	g.ghostRecording.Ticks = 10
+	g.world.Score = 10
+	if delta, _ := g.ghostScoreDelta(); delta != 4 {
+		t.Fatalf("delta = %d, want 4 against the ghost's final score", delta)
+	}
+}
+
+func TestCoopRunsKeepTheirOwnGhost(t *testing.T) {
+	store := &fakeGhostStore{runs: map[string]*ghostRun{}}
+	g := &Game{ghostStore: store, world: &sim.World{Config: sim.Config{Coop: true}}}
+	g.startGhost()
+	g.world.Score = 9
+	g.recordGhost()
+	g.finishGhost()
+	if store.runs["coop"] == nil || store.runs[""] != nil {
+		t.Fatalf("saved ghosts %v, want the co-op run kept apart from the solo ghost", store.runs)
+	}
+}
@@ -15,12 +15,18 @@ type platformHighScoreStore struct {
 	initErr error
 }
 
-func newHighScoreStore() highScoreStore {
+// newHighScoreStore opens the high score file for a variant such as a
+// difficulty. The empty variant is the original file.
+func newHighScoreStore(variant string) highScoreStore {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return &platformHighScoreStore{initErr: err}
 	}
-	return &platformHighScoreStore{path: filepath.Join(configDir, "mygame", "highscore")}
+	name := "highscore"
+	if variant != "" {
+		name += "-" + variant
+	}
+	return &platformHighScoreStore{path: filepath.Join(configDir, "mygame", name)}
 }
 
 func (store *platformHighScoreStore) Load() (int, error) {
@@ -5,6 +5,8 @@ package main
 import (
 	"path/filepath"
 	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
 )
 
 func TestDesktopHighScoreStoreRoundTrip(t *testing.T) {
@@ -20,3 +22,13 @@ func TestDesktopHighScoreStoreRoundTrip(t *testing.T) {
 		t.Fatalf("loaded high score = %d, want 123", score)
 	}
 }
+
+func TestDesktopHighScoreStoreKeepsNormalOnOriginalFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	normal := newHighScoreStore(highScoreVariant(modeWaves, sim.DifficultyNormal, false)).(*platformHighScoreStore)
+	hard := newHighScoreStore(highScoreVariant(modeWaves, sim.DifficultyHard, false)).(*platformHighScoreStore)
+	if filepath.Base(normal.path) != "highscore" || filepath.Base(hard.path) != "highscore-hard" {
+		t.Fatalf("paths = %q and %q, want highscore and highscore-hard", normal.path, hard.path)
+	}
+}
@@ -10,13 +10,21 @@ import (
 
 const highScoreStorageKey = "mygame.highScore"
 
-type platformHighScoreStore struct{}
+type platformHighScoreStore struct {
+	key string
+}
 
-func newHighScoreStore() highScoreStore {
-	return &platformHighScoreStore{}
+// newHighScoreStore uses a localStorage key per variant such as a difficulty.
+// The empty variant is the original key.
+func newHighScoreStore(variant string) highScoreStore {
+	key := highScoreStorageKey
+	if variant != "" {
+		key += "." + variant
+	}
+	return &platformHighScoreStore{key: key}
 }
 
-func (*platformHighScoreStore) Load() (score int, err error) {
+func (store *platformHighScoreStore) Load() (score int, err error) {
 	defer func() {
 		if recovered := recover(); recovered != nil {
 			score = 0
@@ -24,7 +32,7 @@ func (*platformHighScoreStore) Load() (score int, err error) {
 		}
 	}()
 
-	value := js.Global().Get("localStorage").Call("getItem", highScoreStorageKey)
+	value := js.Global().Get("localStorage").Call("getItem", store.key)
 	if value.IsNull() || value.IsUndefined() || value.String() == "" {
 		return 0, nil
 	}
@@ -35,13 +43,13 @@ func (*platformHighScoreStore) Load() (score int, err error) {
 	return max(0, score), nil
 }
 
-func (*platformHighScoreStore) Save(score int) (err error) {
+func (store *platformHighScoreStore) Save(score int) (err error) {
 	defer func() {
 		if recovered := recover(); recovered != nil {
 			err = fmt.Errorf("write localStorage: %v", recovered)
 		}
 	}()
 
-	js.Global().Get("localStorage").Call("setItem", highScoreStorageKey, strconv.Itoa(max(0, score)))
+	js.Global().Get("localStorage").Call("setItem", store.key, strconv.Itoa(max(0, score)))
 	return nil
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+// hotReloadInterval is how often, in ticks, debug builds check the watched
+// files for changes.
+const hotReloadInterval = 30
+
+func (g *Game) watchedAssetPaths() []string {
+	paths := []string{tuningPath}
+	for _, asset := range g.imageAssets() {
+		paths = append(paths, asset.path)
+	}
+	for _, asset := range g.soundAssets() {
+		paths = append(paths, asset.path)
+	}
+	return paths
+}
+
+func (g *Game) updateHotReload() {
+	if g.assetWatcher == nil {
+		return
+	}
+	for _, path := range g.assetWatcher.poll() {
+		g.reloadAsset(path)
+	}
+}
+
+func (g *Game) reloadAsset(path string) {
+	if path == tuningPath {
+		g.loadTuning()
+		return
+	}
+	for _, asset := range g.imageAssets() {
+		if asset.path != path {
+			continue
+		}
+		img, err := loadImage(path)
+		if err != nil {
+			log.Printf("hot reload: %v", err)
+			return
+		}
+		*asset.image = img
+		g.sizes = g.spriteSizes()
+		g.world.Sizes = g.sizes
+		log.Printf("hot reload: reloaded image %s (%dx%d)", path, img.Bounds().Dx(), img.Bounds().Dy())
+		return
+	}
+	for _, asset := range g.soundAssets() {
+		if asset.path != path {
+			continue
+		}
+		effect, err := loadSoundEffect(g.audioContext, asset, g.audioRandom)
+		if err != nil {
+			log.Printf("hot reload: %v", err)
+			return
+		}
+		(*asset.effect).stop()
+		*asset.effect = effect
+		log.Printf("hot reload: reloaded sound %s", path)
+		return
+	}
+}
+
+// loadTuning applies tuningPath on top of the defaults. A missing file means
+// the defaults; a broken file keeps the current values so a typo mid-edit
+// does not reset the balance being tested.
+func (g *Game) loadTuning() {
+	next := sim.DefaultTuning()
+	data, err := readAsset(tuningPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		log.Printf("hot reload: read %s: %v", tuningPath, err)
+		return
+	default:
+		next, err = sim.ParseTuning(data)
+		if err != nil {
+			log.Printf("hot reload: parse %s: %v (keeping current values)", tuningPath, err)
+			return
+		}
+	}
+
+	for _, change := range sim.TuningChanges(tuning, next) {
+		log.Printf("hot reload: %s", change)
+	}
+	tuning = next
+	// An online game keeps its tuning, since the peer cannot see the change.
+	if g.world != nil && g.net == nil {
+		g.world.Tuning = next
+	}
+}
@@ -0,0 +1,60 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// assetWatcher polls modification times instead of using OS notifications so
+// it behaves the same on every desktop platform and needs no extra modules.
+type assetWatcher struct {
+	paths    []string
+	modTimes map[string]time.Time
+	ticks    int
+}
+
+func newAssetWatcher(paths []string) *assetWatcher {
+	watcher := &assetWatcher{
+		paths:    paths,
+		modTimes: make(map[string]time.Time, len(paths)),
+	}
+	for _, path := range paths {
+		watcher.modTimes[path] = assetModTime(path)
+	}
+	return watcher
+}
+
+// poll returns the watched paths that were created, modified or removed since
+// the last check. The file system is only touched every hotReloadInterval
+// ticks.
+func (watcher *assetWatcher) poll() []string {
+	watcher.ticks++
+	if watcher.ticks < hotReloadInterval {
+		return nil
+	}
+	watcher.ticks = 0
+	return watcher.changed()
+}
+
+func (watcher *assetWatcher) changed() []string {
+	var changed []string
+	for _, path := range watcher.paths {
+		modTime := assetModTime(path)
+		if modTime.Equal(watcher.modTimes[path]) {
+			continue
+		}
+		watcher.modTimes[path] = modTime
+		changed = append(changed, path)
+	}
+	return changed
+}
+
+func assetModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,59 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAssetWatcherReportsModifiedCreatedAndRemovedFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "ufo.png")
+	created := filepath.Join(dir, "tuning.json")
+	if err := os.WriteFile(existing, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := newAssetWatcher([]string{existing, created})
+	if changed := watcher.changed(); len(changed) != 0 {
+		t.Fatalf("changed before any edit = %q, want none", changed)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(existing, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(created, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := watcher.changed(); !slices.Equal(changed, []string{existing, created}) {
+		t.Fatalf("changed after edits = %q, want both files", changed)
+	}
+
+	if err := os.Remove(created); err != nil {
+		t.Fatal(err)
+	}
+	if changed := watcher.changed(); !slices.Equal(changed, []string{created}) {
+		t.Fatalf("changed after removal = %q, want %q", changed, created)
+	}
+}
+
+func TestAssetWatcherOnlyPollsEveryInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	watcher := newAssetWatcher([]string{path})
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for tick := 1; tick < hotReloadInterval; tick++ {
+		if changed := watcher.poll(); len(changed) != 0 {
+			t.Fatalf("poll at tick %d = %q, want nothing before the interval", tick, changed)
+		}
+	}
+	if changed := watcher.poll(); !slices.Equal(changed, []string{path}) {
+		t.Fatalf("poll at interval = %q, want %q", changed, path)
+	}
+}
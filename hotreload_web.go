@@ -0,0 +1,15 @@
+//go:build js
+
+package main
+
+// Hot reload watches the local file system, so the web build never has a
+// watcher.
+type assetWatcher struct{}
+
+func newAssetWatcher([]string) *assetWatcher {
+	return nil
+}
+
+func (*assetWatcher) poll() []string {
+	return nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"math"
+	"slices"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+// HUD measurements are in screen pixels at 100% HUD scale.
+const (
+	hudMargin        = 4
+	hudRowGap        = 2
+	hudItemGap       = 5
+	hudGaugeHeight   = 10
+	hudKIEEGaugeSize = 112
+	hudBossBarSize   = 220
+	defaultHUDScale  = 100
+)
+
+// hudScales are the HUD scale percentages offered in the settings menu.
+var hudScales = []int{75, 100, 125, 150}
+
+// fontTier is a text size. Every tier is drawn with the MPlus face so HUD
+// text can show Japanese as well as English.
+type fontTier uint8
+
+const (
+	fontSmall fontTier = iota
+	fontMedium
+	fontLarge
+)
+
+var fontTierSizes = [...]float64{
+	fontSmall:  13,
+	fontMedium: 16,
+	fontLarge:  24,
+}
+
+func newFontFace(source *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(source, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingVertical,
+	})
+}
+
+// fontFace returns the face for a tier at a scale, creating it on first use.
+func (g *Game) fontFace(tier fontTier, scale float64) font.Face {
+	size := math.Round(fontTierSizes[tier] * scale)
+	if face, ok := g.fontFaces[size]; ok {
+		return face
+	}
+	face, err := newFontFace(g.fontSource, size)
+	if err != nil {
+		log.Printf("create %vpx font: %v", size, err)
+		return basicfont.Face7x13
+	}
+	if g.fontFaces == nil {
+		g.fontFaces = map[float64]font.Face{}
+	}
+	g.fontFaces[size] = face
+	return face
+}
+
+func clampHUDScale(scale int) int {
+	if slices.Contains(hudScales, scale) {
+		return scale
+	}
+	return defaultHUDScale
+}
+
+func (g *Game) hudScale() float64 {
+	return float64(clampHUDScale(g.settings.HUDScale)) / 100
+}
+
+func (g *Game) cycleHUDScale(delta int) {
+	index := slices.Index(hudScales, clampHUDScale(g.settings.HUDScale))
+	g.settings.HUDScale = hudScales[min(len(hudScales)-1, max(0, index+delta))]
+	g.saveSettings()
+}
+
+// hudAnchor is the screen corner or edge a group of HUD rows is pinned to.
+type hudAnchor uint8
+
+const (
+	anchorTopLeft hudAnchor = iota
+	anchorTopCenter
+	anchorTopRight
+	anchorBottomLeft
+	anchorBottomCenter
+	anchorBottomRight
+)
+
+func (anchor hudAnchor) bottom() bool {
+	return anchor >= anchorBottomLeft
+}
+
+// anchorPoint is the top-left corner of a width by height box pinned to the
+// anchor, inset from the screen edges by margin.
+func anchorPoint(anchor hudAnchor, width, height, margin float64) (float64, float64) {
+	x := margin
+	switch anchor {
+	case anchorTopCenter, anchorBottomCenter:
+		x = (screenWidth - width) / 2
+	case anchorTopRight, anchorBottomRight:
+		x = screenWidth - margin - width
+	}
+	y := margin
+	if anchor.bottom() {
+		y = screenHeight - margin - height
+	}
+	return x, y
+}
+
+// hudStack places rows away from its anchor: downward from a top anchor and
+// upward from a bottom one.
+type hudStack struct {
+	g      *Game
+	screen *ebiten.Image
+	anchor hudAnchor
+	scale  float64
+	used   float64
+}
+
+func (g *Game) newHUDStack(screen *ebiten.Image, anchor hudAnchor) *hudStack {
+	return &hudStack{g: g, screen: screen, anchor: anchor, scale: g.hudScale()}
+}
+
+func (stack *hudStack) row(width, height float64) (float64, float64) {
+	x, y := anchorPoint(stack.anchor, width, height, hudMargin*stack.scale)
+	if stack.anchor.bottom() {
+		y -= stack.used
+	} else {
+		y += stack.used
+	}
+	stack.used += height + hudRowGap*stack.scale
+	return x, y
+}
+
+func (stack *hudStack) face(tier fontTier) font.Face {
+	return stack.g.fontFace(tier, stack.scale)
+}
+
+func (stack *hudStack) text(message string, tier fontTier, clr color.Color) {
+	face := stack.face(tier)
+	width, height := measureText(face, message)
+	x, y := stack.row(width, height)
+	drawTextAt(stack.screen, message, face, x, y, clr)
+}
+
+// hudGauge is a labeled bar. fillWidth is in pixels of the bar's inside.
+type hudGauge struct {
+	label      string
+	value      string
+	width      int
+	fillWidth  func(inside int) float64
+	background color.Color
+	fill       color.Color
+}
+
+func (stack *hudStack) gauge(gauge hudGauge, tier fontTier, clr color.Color) {
+	face := stack.face(tier)
+	labelWidth, height := measureText(face, gauge.label)
+	valueWidth, _ := measureText(face, gauge.value)
+	gap := hudItemGap * stack.scale
+	barWidth := math.Round(float64(gauge.width) * stack.scale)
+	barHeight := math.Round(hudGaugeHeight * stack.scale)
+	width := labelWidth + gap + barWidth
+	if gauge.value != "" {
+		width += gap + valueWidth
+	}
+	x, y := stack.row(width, max(height, barHeight))
+
+	drawTextAt(stack.screen, gauge.label, face, x, y, clr)
+	barX := x + labelWidth + gap
+	barY := y + (max(height, barHeight)-barHeight)/2
+	ebitenutil.DrawRect(stack.screen, barX, barY, barWidth, barHeight, gauge.background)
+	ebitenutil.DrawRect(stack.screen, barX+1, barY+1, gauge.fillWidth(int(barWidth)-2), barHeight-2, gauge.fill)
+	if gauge.value != "" {
+		drawTextAt(stack.screen, gauge.value, face, barX+barWidth+gap, y, clr)
+	}
+}
+
+func measureText(face font.Face, message string) (float64, float64) {
+	_, advance := font.BoundString(face, message)
+	metrics := face.Metrics()
+	return float64(advance.Ceil()), float64((metrics.Ascent + metrics.Descent).Ceil())
+}
+
+// drawTextAt draws with (x, y) as the top-left of the line instead of the
+// baseline.
+func drawTextAt(screen *ebiten.Image, message string, face font.Face, x, y float64, clr color.Color) {
+	text.Draw(screen, message, face, int(math.Round(x)), int(math.Round(y))+face.Metrics().Ascent.Ceil(), clr)
+}
+
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	status := g.newHUDStack(screen, anchorTopLeft)
+	status.text(g.message("hud.score", g.world.Score, g.highScore), fontMedium, color.White)
+	g.drawGhostHUD(status)
+	status.text(g.waveStatus(), fontSmall, color.White)
+	if g.mode == modeScoreAttack {
+		minutes, seconds := clock(g.world.ModeTicks)
+		clr := color.Color(color.White)
+		if g.world.ModeTicks <= 10*60 {
+			clr = color.RGBA{R: 255, G: 90, B: 70, A: 255}
+		}
+		status.text(g.message("hud.timeLeft", minutes, seconds), fontMedium, clr)
+	}
+	colors := g.palette()
+	if g.world.Boss != nil {
+		hp, maxHP := max(0, g.world.Boss.HP), g.world.Boss.MaxHP
+		status.gauge(hudGauge{
+			label:      g.message("hud.boss"),
+			width:      hudBossBarSize,
+			fillWidth:  func(inside int) float64 { return float64(inside) * float64(hp) / float64(maxHP) },
+			background: colors.bossBackground,
+			fill:       colors.bossFill,
+		}, fontSmall, color.White)
+	}
+
+	g.drawPilotHUD(status, sim.PlayerOne)
+	if g.world.Coop {
+		g.drawPilotHUD(g.partnerHUDStack(screen), sim.PlayerTwo)
+	}
+	if g.world.PowerUpTicks > 0 {
+		seconds := float64(g.world.PowerUpTicks) / 60
+		status.text(g.message("hud.power", sim.PowerUpShotCount, seconds), fontSmall, colors.powerText)
+	}
+
+	modifiers := g.newHUDStack(screen, anchorBottomRight)
+	modifiers.text(g.worldDifficultyName(), fontSmall, color.RGBA{R: 255, G: 220, B: 70, A: 255})
+	if g.assisted {
+		modifiers.text(g.message("hud.assist"), fontSmall, color.RGBA{R: 130, G: 220, B: 255, A: 255})
+	}
+	if g.debug {
+		g.newHUDStack(screen, anchorBottomLeft).text(g.message("hud.debug"), fontSmall, color.RGBA{R: 255, G: 210, B: 60, A: 255})
+	}
+}
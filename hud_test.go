@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestAnchorPointPinsBoxesToScreenEdges(t *testing.T) {
+	tests := []struct {
+		anchor hudAnchor
+		x, y   float64
+	}{
+		{anchorTopLeft, 4, 4},
+		{anchorTopCenter, (screenWidth - 100) / 2, 4},
+		{anchorTopRight, screenWidth - 4 - 100, 4},
+		{anchorBottomLeft, 4, screenHeight - 4 - 20},
+		{anchorBottomCenter, (screenWidth - 100) / 2, screenHeight - 4 - 20},
+		{anchorBottomRight, screenWidth - 4 - 100, screenHeight - 4 - 20},
+	}
+	for _, test := range tests {
+		if x, y := anchorPoint(test.anchor, 100, 20, 4); x != test.x || y != test.y {
+			t.Errorf("anchor %d = (%v,%v), want (%v,%v)", test.anchor, x, y, test.x, test.y)
+		}
+	}
+}
+
+func TestHUDStackGrowsAwayFromItsAnchor(t *testing.T) {
+	top := &hudStack{anchor: anchorTopLeft, scale: 2}
+	if _, y := top.row(50, 10); y != 2*hudMargin {
+		t.Fatalf("first top row y = %v, want %v", y, 2*hudMargin)
+	}
+	if _, y := top.row(50, 10); y != 2*hudMargin+10+2*hudRowGap {
+		t.Fatalf("second top row y = %v, want below the first", y)
+	}
+
+	bottom := &hudStack{anchor: anchorBottomLeft, scale: 1}
+	first := screenHeight - hudMargin - 10.0
+	if _, y := bottom.row(50, 10); y != first {
+		t.Fatalf("first bottom row y = %v, want %v", y, first)
+	}
+	if _, y := bottom.row(50, 10); y != first-10-hudRowGap {
+		t.Fatalf("second bottom row y = %v, want above the first", y)
+	}
+}
+
+func TestHUDScaleCyclesWithinOptions(t *testing.T) {
+	g := &Game{}
+	if got := g.hudScale(); got != 1 {
+		t.Fatalf("unset HUD scale = %v, want 1", got)
+	}
+	g.cycleHUDScale(1)
+	g.cycleHUDScale(1)
+	g.cycleHUDScale(1)
+	if g.settings.HUDScale != hudScales[len(hudScales)-1] {
+		t.Fatalf("HUD scale = %d, want to stop at the largest option", g.settings.HUDScale)
+	}
+	g.settings.HUDScale = 33
+	g.cycleHUDScale(-1)
+	if g.settings.HUDScale != 75 {
+		t.Fatalf("HUD scale from an invalid value = %d, want one step below the default", g.settings.HUDScale)
+	}
+}
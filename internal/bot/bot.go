@@ -0,0 +1,286 @@
+// Package bot plays the game through the same controls a player has. The
+// balance tool runs it through thousands of games and the title screen shows
+// it off while nobody is playing.
+package bot
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const (
+	dodgeMargin = 6 // Extra pixels kept between the hitbox and a falling enemy.
+	ebiMargin   = 4 // Extra pixels around an ebi that block a shot.
+	hitMargin   = 4 // Pixels a lead may be off and still count as on target.
+)
+
+// Skill is how well a bot plays. A slower reaction holds each decision for
+// longer, aim error throws its lead off and a short lookahead notices falling
+// enemies late.
+type Skill struct {
+	Name          string
+	ReactionTicks int     // Ticks between decisions.
+	AimError      float64 // Pixels the aim can be off, either way.
+	Lookahead     int     // Ticks ahead the bot watches for falling enemies.
+	UsesKIEE      bool
+}
+
+// Skills are the presets, from weakest to strongest.
+var Skills = []Skill{
+	{Name: "novice", ReactionTicks: 14, AimError: 24, Lookahead: 30},
+	{Name: "average", ReactionTicks: 7, AimError: 10, Lookahead: 50, UsesKIEE: true},
+	{Name: "expert", ReactionTicks: 2, AimError: 2, Lookahead: 80, UsesKIEE: true},
+}
+
+// SkillNamed looks up a preset by name.
+func SkillNamed(name string) (Skill, bool) {
+	for _, skill := range Skills {
+		if skill.Name == name {
+			return skill, true
+		}
+	}
+	return Skill{}, false
+}
+
+// Bot plays one player. It keeps its controls between decisions, so give
+// each player their own bot.
+type Bot struct {
+	skill    Skill
+	random   *rand.Rand
+	wait     int
+	aimError float64
+	held     sim.Controls
+}
+
+// New makes a bot whose aim error comes from seed, so the same seed plays
+// the same game.
+func New(skill Skill, seed int64) *Bot {
+	return &Bot{skill: skill, random: rand.New(rand.NewSource(seed))}
+}
+
+func (b *Bot) Skill() Skill {
+	return b.skill
+}
+
+// Controls returns the player's controls for the next tick. Between decisions
+// the bot keeps holding what it chose, but the special attack is only
+// pressed once.
+func (b *Bot) Controls(w *sim.World, player int) sim.Controls {
+	if b.wait > 0 {
+		b.wait--
+		b.held.Special = false
+		return b.held
+	}
+	b.wait = max(0, b.skill.ReactionTicks-1)
+	b.aimError = (b.random.Float64()*2 - 1) * b.skill.AimError
+	b.held = b.decide(w, player)
+	return b.held
+}
+
+// threat is a falling enemy's column and when it reaches the player.
+type threat struct {
+	left, right float64
+	ticks       float64
+}
+
+// view is what the bot works out about the world before deciding.
+type view struct {
+	world   *sim.World
+	player  int
+	x       float64 // The player's sprite left edge.
+	offset  float64 // From the sprite's left edge to the hitbox's.
+	width   float64 // Hitbox width.
+	shotY   float64
+	threats []threat
+}
+
+func (b *Bot) look(w *sim.World, player int) view {
+	rect := w.PilotRect(player)
+	p := w.Pilots[player]
+	v := view{
+		world:  w,
+		player: player,
+		x:      p.Position.X,
+		offset: float64(rect.Min.X) - p.Position.X,
+		width:  float64(rect.Dx()),
+		shotY:  p.Position.Y,
+	}
+	speed := w.FallingSpeed()
+	enemy := w.Sizes.BashiHebi
+	for _, position := range w.BashiHebis {
+		if position.Y > float64(rect.Max.Y) {
+			continue
+		}
+		ticks := max(0, (float64(rect.Min.Y)-position.Y-float64(enemy.Y))/speed)
+		if ticks > float64(b.skill.Lookahead) {
+			continue
+		}
+		v.threats = append(v.threats, threat{
+			left:  position.X - dodgeMargin,
+			right: position.X + float64(enemy.X) + dodgeMargin,
+			ticks: ticks,
+		})
+	}
+	return v
+}
+
+// safe reports whether the hitbox at sprite position x stays clear of every
+// enemy that lands within the next ticks.
+func (v view) safe(x, ticks float64) bool {
+	left := x + v.offset
+	right := left + v.width
+	for _, danger := range v.threats {
+		if danger.ticks <= ticks && right > danger.left && left < danger.right {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Bot) decide(w *sim.World, player int) sim.Controls {
+	p := w.Pilots[player]
+	if p.Down {
+		return sim.Controls{}
+	}
+	v := b.look(w, player)
+	speed := w.Tuning.PlayerSpeed
+	shotOffset := w.ShotX(player) - p.Position.X
+
+	goal, onTarget := b.aim(v, shotOffset)
+	if partner := 1 - player; w.Coop && !w.Versus && w.Pilots[partner].Down {
+		// Reviving the partner beats scoring.
+		goal, onTarget = w.Pilots[partner].Position.X, false
+	}
+	horizon := float64(b.skill.Lookahead)
+	target := goal
+	if !v.safe(v.x, horizon) {
+		target = v.escape(speed)
+	} else if step := v.x + math.Copysign(speed*float64(b.skill.ReactionTicks), goal-v.x); math.Abs(goal-v.x) > speed && !v.safe(step, horizon) {
+		target = v.x
+	}
+
+	var controls sim.Controls
+	switch {
+	case target < v.x-speed/2:
+		controls.Left = true
+	case target > v.x+speed/2:
+		controls.Right = true
+	}
+	controls.Fire = onTarget && !ebiInLine(w, w.ShotX(player), v.shotY)
+	controls.Special = b.useKIEE(v, p)
+	return controls
+}
+
+// escape finds the nearest sprite position the player can reach before the
+// enemies land, or stays put if there is none.
+func (v view) escape(speed float64) float64 {
+	limit := float64(sim.ScreenWidth) - v.world.Sizes.PilotWidth()
+	for distance := speed; distance <= limit; distance += speed {
+		ticks := distance / speed
+		for _, x := range []float64{v.x - distance, v.x + distance} {
+			if x < 0 || x > limit {
+				continue
+			}
+			if v.safe(x, math.Inf(1)) && v.pathClear(x, ticks) {
+				return x
+			}
+		}
+	}
+	return v.x
+}
+
+// pathClear reports whether nothing lands on the way to x before the player
+// gets past it.
+func (v view) pathClear(x, ticks float64) bool {
+	left, right := min(v.x, x)+v.offset, max(v.x, x)+v.offset+v.width
+	for _, danger := range v.threats {
+		if danger.ticks <= ticks && right > danger.left && left < danger.right {
+			return false
+		}
+	}
+	return true
+}
+
+// aim picks the sprite position to shoot from: under the boss, or where the
+// shot meets the UFO that is cheapest to reach. It also reports whether a
+// shot fired now would hit.
+func (b *Bot) aim(v view, shotOffset float64) (float64, bool) {
+	w := v.world
+	shotX := v.x + shotOffset
+	projectileSpeed := w.Tuning.ProjectileSpeed
+	if w.Boss != nil {
+		rect := w.BossRect()
+		center := float64(rect.Min.X+rect.Max.X)/2 + b.aimError
+		return center - shotOffset, shotX >= float64(rect.Min.X) && shotX <= float64(rect.Max.X)
+	}
+
+	best, bestCost, onTarget := v.x, math.Inf(1), false
+	ufo := w.Sizes.UFO
+	for _, target := range w.UFOs {
+		if !target.Visible {
+			continue
+		}
+		centerY := target.Y + float64(ufo.Y)/2
+		flight := (v.shotY - centerY) / projectileSpeed
+		if flight <= 0 {
+			continue
+		}
+		// Where the UFO will be when a shot fired now arrives.
+		now := target.X + float64(ufo.X)/2 + target.VelocityX*flight
+		if math.Abs(now-shotX) <= float64(ufo.X)/2+hitMargin {
+			onTarget = true
+		}
+		// Moving there takes time too, so lead by the walk as well.
+		walk := math.Abs(now-shotX) / w.Tuning.PlayerSpeed
+		lead := now + target.VelocityX*walk + b.aimError
+		if lead < 0 || lead > sim.ScreenWidth {
+			continue
+		}
+		if cost := math.Abs(lead - shotX); cost < bestCost {
+			best, bestCost = lead-shotOffset, cost
+		}
+	}
+	return best, onTarget
+}
+
+// ebiInLine reports whether a shot fired now from (x, shotY) would meet an
+// ebi.
+func ebiInLine(w *sim.World, x, shotY float64) bool {
+	size := w.Sizes.Ebi
+	shotWidth := float64(w.Sizes.Projectile.X)
+	for _, ebi := range w.Ebis {
+		flight := (shotY - ebi.Y - float64(size.Y)/2) / w.Tuning.ProjectileSpeed
+		if flight <= 0 {
+			continue
+		}
+		left := ebi.X + ebi.VelocityX*flight - ebiMargin
+		if x+shotWidth > left && x < left+float64(size.X)+2*ebiMargin {
+			return true
+		}
+	}
+	return false
+}
+
+// useKIEE fires the special attack once it is charged and worth it: on a
+// boss, on a screen full of UFOs, or when there is no way out.
+func (b *Bot) useKIEE(v view, p sim.Pilot) bool {
+	w := v.world
+	if !b.skill.UsesKIEE || p.MissCount < w.SpecialCost() {
+		return false
+	}
+	if w.Boss != nil {
+		return true
+	}
+	visible := 0
+	for _, target := range w.UFOs {
+		if target.Visible && target.X+float64(w.Sizes.UFO.X) >= 0 && target.X <= sim.ScreenWidth {
+			visible++
+		}
+	}
+	if visible >= 3 {
+		return true
+	}
+	return !v.safe(v.x, float64(b.skill.ReactionTicks)) && v.escape(w.Tuning.PlayerSpeed) == v.x
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+// gameSizes are the sizes of the game's sprites.
+var gameSizes = sim.Sizes{
+	Player:     image.Pt(797, 1984),
+	UFO:        image.Pt(39, 31),
+	Projectile: image.Pt(10, 10),
+	BashiHebi:  image.Pt(40, 59),
+	Ebi:        image.Pt(30, 34),
+	Boss:       image.Pt(1254, 1254),
+}
+
+func expert(t *testing.T) Skill {
+	t.Helper()
+	skill, ok := SkillNamed("expert")
+	if !ok {
+		t.Fatal("no expert skill")
+	}
+	return skill
+}
+
+func TestBotDodgesFallingEnemies(t *testing.T) {
+	w := sim.New(sim.Config{Tuning: sim.DefaultTuning()}, gameSizes)
+	rect := w.PilotRect(sim.PlayerOne)
+	w.BashiHebis = []sim.Point{{X: float64(rect.Min.X) - 10, Y: float64(rect.Min.Y) - 59 - 30}}
+	player := New(expert(t), 1)
+	controls := player.Controls(w, sim.PlayerOne)
+	if !controls.Left && !controls.Right {
+		t.Fatal("bot stood still under a falling enemy")
+	}
+	for range 120 {
+		w.Step([2]sim.Controls{player.Controls(w, sim.PlayerOne)})
+	}
+	if w.Over {
+		t.Fatal("bot was hit by the falling enemy")
+	}
+}
+
+func TestBotHoldsFireWhenAnEbiIsInTheWay(t *testing.T) {
+	w := sim.New(sim.Config{Tuning: sim.DefaultTuning()}, gameSizes)
+	shotX := w.ShotX(sim.PlayerOne)
+	w.UFOs = []sim.UFO{{HorizontalEnemy: sim.HorizontalEnemy{Point: sim.Point{X: shotX - 15, Y: 60}}, Visible: true}}
+	w.Ebis = []sim.HorizontalEnemy{{Point: sim.Point{X: shotX - 10, Y: 150}}}
+	if New(expert(t), 1).Controls(w, sim.PlayerOne).Fire {
+		t.Fatal("bot fired through an ebi")
+	}
+	w.Ebis = nil
+	if !New(expert(t), 1).Controls(w, sim.PlayerOne).Fire {
+		t.Fatal("bot held fire with a UFO straight above")
+	}
+}
+
+func TestBotUsesKIEEOnTheBoss(t *testing.T) {
+	w := sim.New(sim.Config{Tuning: sim.DefaultTuning()}, gameSizes)
+	w.StartWave(sim.BossWaveCycle)
+	w.Pilots[sim.PlayerOne].MissCount = w.SpecialCost()
+	if !New(expert(t), 1).Controls(w, sim.PlayerOne).Special {
+		t.Fatal("expert kept a full gauge against the boss")
+	}
+	novice, _ := SkillNamed("novice")
+	if New(novice, 1).Controls(w, sim.PlayerOne).Special {
+		t.Fatal("novice used KIEE")
+	}
+}
+
+func TestBotPressesSpecialOnce(t *testing.T) {
+	w := sim.New(sim.Config{Tuning: sim.DefaultTuning()}, gameSizes)
+	w.StartWave(sim.BossWaveCycle)
+	w.Pilots[sim.PlayerOne].MissCount = w.SpecialCost()
+	skill := expert(t)
+	skill.ReactionTicks = 5
+	player := New(skill, 1)
+	player.Controls(w, sim.PlayerOne)
+	for range skill.ReactionTicks - 1 {
+		if player.Controls(w, sim.PlayerOne).Special {
+			t.Fatal("special held between decisions")
+		}
+	}
+}
+
+// wavesReached plays a game and returns the wave it ended in.
+func wavesReached(skill Skill, seed int64) int {
+	w := sim.New(sim.Config{Tuning: sim.DefaultTuning(), Seed: seed}, gameSizes)
+	player := New(skill, seed)
+	for range 20 * 60 * 60 {
+		w.Step([2]sim.Controls{player.Controls(w, sim.PlayerOne)})
+		if w.Over {
+			break
+		}
+	}
+	return w.Wave
+}
+
+func TestSkillsAreOrdered(t *testing.T) {
+	previous := 0
+	for _, skill := range Skills {
+		total := 0
+		for seed := range int64(20) {
+			total += wavesReached(skill, seed)
+		}
+		if total <= previous {
+			t.Errorf("%s reached %d waves over 20 games, no more than the skill below", skill.Name, total)
+		}
+		previous = total
+	}
+}
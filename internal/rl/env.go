@@ -0,0 +1,159 @@
+// Package rl wraps the simulation in a Gym-like environment for training
+// agents: Reset starts a game from a seed and Step plays one action, returning
+// what the agent sees, the reward it earned and whether the game is over.
+// The agent plays player one alone.
+package rl
+
+import (
+	"fmt"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const (
+	defaultFrameSkip = 4  // Ticks each action is held, as in most Atari setups.
+	DeathPenalty     = 10 // Taken from the reward each time an enemy touches the player.
+)
+
+// Action is one of the moves an agent can make each step.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionLeft
+	ActionRight
+	ActionFire
+	ActionLeftFire
+	ActionRightFire
+	ActionSpecial
+)
+
+// Actions names the actions in order, so Actions[action] describes action.
+var Actions = []string{"none", "left", "right", "fire", "leftFire", "rightFire", "special"}
+
+func (action Action) controls() sim.Controls {
+	switch action {
+	case ActionLeft:
+		return sim.Controls{Left: true}
+	case ActionRight:
+		return sim.Controls{Right: true}
+	case ActionFire:
+		return sim.Controls{Fire: true}
+	case ActionLeftFire:
+		return sim.Controls{Left: true, Fire: true}
+	case ActionRightFire:
+		return sim.Controls{Right: true, Fire: true}
+	case ActionSpecial:
+		return sim.Controls{Special: true}
+	}
+	return sim.Controls{}
+}
+
+// Options are the rules every game of an environment plays by.
+type Options struct {
+	Config    sim.Config
+	Sizes     sim.Sizes
+	FrameSkip int // Ticks each action is held; 0 means defaultFrameSkip.
+	MaxSteps  int // Steps after which a game is done anyway; 0 means no limit.
+}
+
+// Spec describes the actions and observations, for clients that size their
+// networks from it.
+type Spec struct {
+	Actions      []string `json:"actions"`
+	FeatureCount int      `json:"featureCount"`
+	FrameWidth   int      `json:"frameWidth"`
+	FrameHeight  int      `json:"frameHeight"`
+	FrameSkip    int      `json:"frameSkip"`
+	MaxSteps     int      `json:"maxSteps"`
+}
+
+// Env is one game at a time. It is not safe for concurrent use; give each
+// worker its own.
+type Env struct {
+	options Options
+	world   *sim.World
+	steps   int
+	died    bool
+}
+
+// New makes an environment and starts its first game with seed 0.
+func New(options Options) *Env {
+	if options.FrameSkip <= 0 {
+		options.FrameSkip = defaultFrameSkip
+	}
+	options.Config.Coop = false
+	options.Config.Versus = false
+	env := &Env{options: options}
+	env.Reset(0)
+	return env
+}
+
+func (env *Env) Spec() Spec {
+	return Spec{
+		Actions:      Actions,
+		FeatureCount: FeatureCount,
+		FrameWidth:   FrameWidth,
+		FrameHeight:  FrameHeight,
+		FrameSkip:    env.options.FrameSkip,
+		MaxSteps:     env.options.MaxSteps,
+	}
+}
+
+// World is the game being played, for logging how it went.
+func (env *Env) World() *sim.World {
+	return env.world
+}
+
+// Reset starts a new game. The same seed and actions play the same game.
+func (env *Env) Reset(seed int64) Observation {
+	config := env.options.Config
+	config.Seed = seed
+	config.OnEvent = func(event sim.Event) {
+		if _, ok := event.(sim.PlayerDied); ok {
+			env.died = true
+		}
+	}
+	env.world = sim.New(config, env.options.Sizes)
+	env.steps = 0
+	env.died = false
+	return observe(env.world)
+}
+
+// Step holds action for FrameSkip ticks. The reward is the score gained, less
+// DeathPenalty if an enemy touched the player. Once done, Step changes nothing
+// until the next Reset.
+func (env *Env) Step(action Action) (Observation, float64, bool) {
+	if env.done() {
+		return observe(env.world), 0, true
+	}
+	score := env.world.Score
+	controls := [2]sim.Controls{action.controls()}
+	for range env.options.FrameSkip {
+		env.world.Step(controls)
+		// Special is a press, not a hold.
+		controls[sim.PlayerOne].Special = false
+		if env.world.Over {
+			break
+		}
+	}
+	env.steps++
+	reward := float64(env.world.Score - score)
+	if env.died {
+		reward -= DeathPenalty
+		env.died = false
+	}
+	return observe(env.world), reward, env.done()
+}
+
+func (env *Env) done() bool {
+	return env.world.Over || (env.options.MaxSteps > 0 && env.steps >= env.options.MaxSteps)
+}
+
+// ParseAction checks an action number from a client.
+func ParseAction(value int) (Action, error) {
+	if value < 0 || value >= len(Actions) {
+		return 0, fmt.Errorf("unknown action %d, want 0 to %d", value, len(Actions)-1)
+	}
+	return Action(value), nil
+}
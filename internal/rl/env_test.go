@@ -0,0 +1,117 @@
+package rl
+
+import (
+	"image"
+	"slices"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+var gameSizes = sim.Sizes{
+	Player:     image.Pt(797, 1984),
+	UFO:        image.Pt(39, 31),
+	Projectile: image.Pt(10, 10),
+	BashiHebi:  image.Pt(40, 59),
+	Ebi:        image.Pt(30, 34),
+	Boss:       image.Pt(1254, 1254),
+}
+
+func newEnv(maxSteps int) *Env {
+	return New(Options{Config: sim.Config{Tuning: sim.DefaultTuning()}, Sizes: gameSizes, MaxSteps: maxSteps})
+}
+
+// play fires while sweeping across the screen until the game is done.
+func play(env *Env, seed int64) (rewards []float64, last Observation) {
+	last = env.Reset(seed)
+	for step := 0; ; step++ {
+		action := ActionLeftFire
+		if step/40%2 == 0 {
+			action = ActionRightFire
+		}
+		observation, reward, done := env.Step(action)
+		rewards = append(rewards, reward)
+		last = observation
+		if done {
+			return rewards, last
+		}
+	}
+}
+
+func TestSameSeedPlaysTheSameGame(t *testing.T) {
+	env := newEnv(600)
+	first, firstLast := play(env, 7)
+	second, secondLast := play(env, 7)
+	if !slices.Equal(first, second) || !slices.Equal(firstLast.Features, secondLast.Features) || !slices.Equal(firstLast.Frame, secondLast.Frame) {
+		t.Fatal("two games with the same seed and actions differed")
+	}
+}
+
+func TestRewardIsTheScoreLessDeaths(t *testing.T) {
+	env := newEnv(0)
+	rewards, _ := play(env, 3)
+	total := 0.0
+	for _, reward := range rewards {
+		total += reward
+	}
+	if want := float64(env.World().Score) - DeathPenalty; total != want {
+		t.Fatalf("rewards add up to %v, want the score %d less one death", total, env.World().Score)
+	}
+	if rewards[len(rewards)-1] > -DeathPenalty+5 {
+		t.Fatalf("last reward %v does not carry the death penalty", rewards[len(rewards)-1])
+	}
+	if _, reward, done := env.Step(ActionFire); reward != 0 || !done {
+		t.Fatal("a finished game kept playing")
+	}
+}
+
+func TestMaxStepsEndsTheGame(t *testing.T) {
+	env := newEnv(5)
+	rewards, _ := play(env, 1)
+	if len(rewards) != 5 || env.World().Over {
+		t.Fatalf("played %d steps, want the limit of 5 to end the game", len(rewards))
+	}
+}
+
+func TestObservationShapes(t *testing.T) {
+	env := newEnv(0)
+	observation := env.Reset(1)
+	if len(observation.Features) != FeatureCount || len(observation.Frame) != FrameWidth*FrameHeight {
+		t.Fatalf("%d features and %d pixels, want %d and %d", len(observation.Features), len(observation.Frame), FeatureCount, FrameWidth*FrameHeight)
+	}
+	if x := observation.Features[0]; x < 0.45 || x > 0.55 {
+		t.Fatalf("player x = %v, want the middle of the screen", x)
+	}
+	bottom := observation.Frame[(FrameHeight-1)*FrameWidth:]
+	if bottom[FrameWidth/2] != shadePlayer.Y || bottom[0] != 0 {
+		t.Fatal("the player is not drawn at the bottom center of the frame")
+	}
+}
+
+func TestFeaturesListTheNearestThreatFirst(t *testing.T) {
+	env := newEnv(0)
+	w := env.World()
+	player := w.Pilots[sim.PlayerOne].Position
+	w.BashiHebis = []sim.Point{{X: 10, Y: 0}, {X: player.X, Y: player.Y - 100}}
+	w.UFOs = []sim.UFO{{HorizontalEnemy: sim.HorizontalEnemy{Point: sim.Point{X: 600, Y: 50}, VelocityX: -3}, Visible: true}}
+	features := features(w)
+	threats := features[playerFeatures:]
+	if threats[0] != 1 || threats[3] != 1 || threats[6] != 0 || threats[1] <= threats[4] {
+		t.Fatalf("threats = %v, want the one above the player first and a missing third", threats[:9])
+	}
+	ufos := threats[MaxThreats*threatFeatures:]
+	if ufos[0] != 1 || ufos[1] <= 0 || ufos[3] >= 0 || ufos[4] != 0 {
+		t.Fatalf("ufos = %v, want one UFO to the right flying left", ufos[:8])
+	}
+}
+
+func TestParseActionRejectsUnknownActions(t *testing.T) {
+	if action, err := ParseAction(int(ActionSpecial)); err != nil || action != ActionSpecial {
+		t.Fatalf("ParseAction(%d) = %v, %v", ActionSpecial, action, err)
+	}
+	for _, value := range []int{-1, len(Actions)} {
+		if _, err := ParseAction(value); err == nil {
+			t.Fatalf("action %d accepted", value)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package rl
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"slices"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const (
+	MaxThreats = 3 // Falling enemies in the features, nearest first.
+	MaxUFOs    = 4 // Visible UFOs in the features, nearest to the shot line first.
+	MaxEbis    = 2 // Ebis in the features, nearest to the shot line first.
+
+	playerFeatures = 6
+	threatFeatures = 3
+	movingFeatures = 4
+
+	// FeatureCount is the length of Observation.Features.
+	FeatureCount = playerFeatures + MaxThreats*threatFeatures + (MaxUFOs+MaxEbis)*movingFeatures
+
+	frameScale  = 8
+	FrameWidth  = sim.ScreenWidth / frameScale
+	FrameHeight = sim.ScreenHeight / frameScale
+)
+
+// Shades of the sprites in the frame, brighter for what matters more.
+var (
+	shadePlayer     = color.Gray{Y: 255}
+	shadeProjectile = color.Gray{Y: 230}
+	shadeThreat     = color.Gray{Y: 200}
+	shadePowerUp    = color.Gray{Y: 180}
+	shadeUFO        = color.Gray{Y: 150}
+	shadeEbi        = color.Gray{Y: 100}
+	shadeBoss       = color.Gray{Y: 70}
+)
+
+// Observation is what the agent sees after a step.
+//
+// Features are in screen widths and heights, so most lie in -1 to 1:
+//
+//   - the player: center x, KIEE charge, power-up time left, and whether a
+//     boss is up with its center x and HP left
+//   - MaxThreats falling enemies: present, x and y of their center relative
+//     to the player's
+//   - MaxUFOs UFOs and then MaxEbis ebis: present, x and y of their center
+//     relative to where the player's shots leave, and speed in screen widths
+//     per second
+//
+// Missing enemies are all zeros. Frame is the screen shrunk to FrameWidth by
+// FrameHeight grayscale pixels, row by row, with each sprite drawn as a
+// rectangle in its own shade; JSON carries it as base64.
+type Observation struct {
+	Features []float64 `json:"features"`
+	Frame    []byte    `json:"frame"`
+}
+
+func observe(w *sim.World) Observation {
+	return Observation{Features: features(w), Frame: frame(w)}
+}
+
+func features(w *sim.World) []float64 {
+	features := make([]float64, 0, FeatureCount)
+	pilot := w.Pilots[sim.PlayerOne]
+	center := sim.Point{
+		X: pilot.Position.X + w.Sizes.PilotWidth()/2,
+		Y: pilot.Position.Y + w.Sizes.PilotHeight()/2,
+	}
+	features = append(features,
+		center.X/sim.ScreenWidth,
+		min(1, float64(pilot.MissCount)/float64(w.SpecialCost())),
+		float64(w.PowerUpTicks)/float64(max(1, w.Tuning.PowerUpDuration)),
+	)
+	if w.Boss != nil {
+		features = append(features, 1,
+			(w.Boss.X+w.Sizes.BossWidth()/2)/sim.ScreenWidth,
+			float64(w.Boss.HP)/float64(max(1, w.Boss.MaxHP)))
+	} else {
+		features = append(features, 0, 0, 0)
+	}
+
+	threats := make([]sim.Point, len(w.BashiHebis))
+	for index, enemy := range w.BashiHebis {
+		threats[index] = centerOf(enemy, w.Sizes.BashiHebi)
+	}
+	slices.SortFunc(threats, func(a, b sim.Point) int {
+		return cmp.Compare(distance(a, center), distance(b, center))
+	})
+	for index := range MaxThreats {
+		if index >= len(threats) {
+			features = append(features, 0, 0, 0)
+			continue
+		}
+		features = append(features, 1,
+			(threats[index].X-center.X)/sim.ScreenWidth,
+			(threats[index].Y-center.Y)/sim.ScreenHeight)
+	}
+
+	shot := sim.Point{X: w.ShotX(sim.PlayerOne) + float64(w.Sizes.Projectile.X)/2, Y: pilot.Position.Y}
+	var ufos []sim.HorizontalEnemy
+	for _, target := range w.UFOs {
+		if target.Visible {
+			ufos = append(ufos, target.HorizontalEnemy)
+		}
+	}
+	features = appendMoving(features, ufos, w.Sizes.UFO, shot, MaxUFOs)
+	return appendMoving(features, w.Ebis, w.Sizes.Ebi, shot, MaxEbis)
+}
+
+// appendMoving adds the count enemies nearest to the shot line.
+func appendMoving(features []float64, enemies []sim.HorizontalEnemy, size image.Point, shot sim.Point, count int) []float64 {
+	enemies = slices.Clone(enemies)
+	slices.SortFunc(enemies, func(a, b sim.HorizontalEnemy) int {
+		return cmp.Compare(math.Abs(centerOf(a.Point, size).X-shot.X), math.Abs(centerOf(b.Point, size).X-shot.X))
+	})
+	for index := range count {
+		if index >= len(enemies) {
+			features = append(features, 0, 0, 0, 0)
+			continue
+		}
+		enemy := centerOf(enemies[index].Point, size)
+		features = append(features, 1,
+			(enemy.X-shot.X)/sim.ScreenWidth,
+			(enemy.Y-shot.Y)/sim.ScreenHeight,
+			enemies[index].VelocityX*60/sim.ScreenWidth)
+	}
+	return features
+}
+
+func centerOf(position sim.Point, size image.Point) sim.Point {
+	return sim.Point{X: position.X + float64(size.X)/2, Y: position.Y + float64(size.Y)/2}
+}
+
+func distance(a, b sim.Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// frame draws the world at one pixel per frameScale screen pixels. Sprites
+// are rounded outwards, so even a shot covers at least one pixel.
+func frame(w *sim.World) []byte {
+	frame := image.NewGray(image.Rect(0, 0, FrameWidth, FrameHeight))
+	fill := func(position sim.Point, width, height float64, shade color.Gray) {
+		rect := image.Rect(
+			int(math.Floor(position.X/frameScale)),
+			int(math.Floor(position.Y/frameScale)),
+			int(math.Ceil((position.X+width)/frameScale)),
+			int(math.Ceil((position.Y+height)/frameScale)),
+		)
+		draw.Draw(frame, rect, image.NewUniform(shade), image.Point{}, draw.Src)
+	}
+	sized := func(position sim.Point, size image.Point, shade color.Gray) {
+		fill(position, float64(size.X), float64(size.Y), shade)
+	}
+
+	if w.Boss != nil {
+		fill(w.Boss.Point, w.Sizes.BossWidth(), w.Sizes.BossHeight(), shadeBoss)
+	}
+	for _, target := range w.Ebis {
+		sized(target.Point, w.Sizes.Ebi, shadeEbi)
+	}
+	for _, target := range w.UFOs {
+		if target.Visible {
+			sized(target.Point, w.Sizes.UFO, shadeUFO)
+		}
+	}
+	for _, item := range w.PowerUps {
+		fill(item.Point, sim.PowerUpSize, sim.PowerUpSize, shadePowerUp)
+	}
+	for _, enemy := range w.BashiHebis {
+		sized(enemy, w.Sizes.BashiHebi, shadeThreat)
+	}
+	for _, projectile := range w.Projectiles {
+		sized(projectile.Point, w.Sizes.Projectile, shadeProjectile)
+	}
+	pilot := w.Pilots[sim.PlayerOne]
+	fill(pilot.Position, w.Sizes.PilotWidth(), w.Sizes.PilotHeight(), shadePlayer)
+	return frame.Pix
+}
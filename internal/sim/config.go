@@ -0,0 +1,40 @@
+package sim
+
+import (
+	"fmt"
+	"os"
+)
+
+// LoadConfig builds the rules of a headless tool from its -difficulty, -mode
+// and -tuning flags. "normal" and "waves" name the empty difficulty and mode,
+// and an empty tuning path keeps the defaults.
+func LoadConfig(difficulty, mode, tuningPath string) (Config, error) {
+	config := Config{Tuning: DefaultTuning()}
+	if difficulty != "normal" {
+		config.Difficulty = Difficulty(difficulty)
+	}
+	if !KnownDifficulty(config.Difficulty) {
+		return Config{}, fmt.Errorf("unknown difficulty %q", difficulty)
+	}
+	if mode != "waves" {
+		config.Mode = Mode(mode)
+	}
+	known := false
+	for _, candidate := range Modes {
+		known = known || candidate == config.Mode
+	}
+	if !known {
+		return Config{}, fmt.Errorf("unknown mode %q", mode)
+	}
+	if tuningPath != "" {
+		data, err := os.ReadFile(tuningPath)
+		if err != nil {
+			return Config{}, err
+		}
+		config.Tuning, err = ParseTuning(data)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse %s: %w", tuningPath, err)
+		}
+	}
+	return config, nil
+}
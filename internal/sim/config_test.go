@@ -0,0 +1,16 @@
+package sim
+
+import "testing"
+
+func TestLoadConfigRejectsUnknownNames(t *testing.T) {
+	config, err := LoadConfig("hard", "bossRush", "")
+	if err != nil || config.Difficulty != DifficultyHard || config.Mode != ModeBossRush {
+		t.Fatalf("config = %+v, %v, want hard boss rush", config, err)
+	}
+	if _, err := LoadConfig("impossible", "waves", ""); err == nil {
+		t.Fatal("unknown difficulty accepted")
+	}
+	if _, err := LoadConfig("normal", "marathon", ""); err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+}
@@ -0,0 +1,92 @@
+package sim
+
+import "math"
+
+// Difficulty names a preset. The empty difficulty is Normal, which plays
+// exactly like the wave curve in the tuning.
+type Difficulty string
+
+const (
+	DifficultyEasy    Difficulty = "easy"
+	DifficultyNormal  Difficulty = ""
+	DifficultyHard    Difficulty = "hard"
+	DifficultyLunatic Difficulty = "lunatic"
+)
+
+// Difficulties lists the presets from the easiest to the hardest.
+var Difficulties = []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard, DifficultyLunatic}
+
+// difficultyPreset multiplies the wave curve. Attack time and drop rate are
+// in ticks and "one in N", so larger values there make the game easier.
+type difficultyPreset struct {
+	enemySpeed      float64
+	fallingSpeed    float64
+	spawnRate       float64
+	bossHP          float64
+	bossAttackTime  float64
+	powerUpDropRate float64
+}
+
+var difficultyPresets = map[Difficulty]difficultyPreset{
+	DifficultyEasy:    {enemySpeed: 0.75, fallingSpeed: 0.75, spawnRate: 0.7, bossHP: 0.7, bossAttackTime: 1.4, powerUpDropRate: 0.6},
+	DifficultyNormal:  {enemySpeed: 1, fallingSpeed: 1, spawnRate: 1, bossHP: 1, bossAttackTime: 1, powerUpDropRate: 1},
+	DifficultyHard:    {enemySpeed: 1.25, fallingSpeed: 1.2, spawnRate: 1.3, bossHP: 1.4, bossAttackTime: 0.75, powerUpDropRate: 1.4},
+	DifficultyLunatic: {enemySpeed: 1.5, fallingSpeed: 1.45, spawnRate: 1.7, bossHP: 2, bossAttackTime: 0.5, powerUpDropRate: 2},
+}
+
+func KnownDifficulty(level Difficulty) bool {
+	_, ok := difficultyPresets[level]
+	return ok
+}
+
+// difficultyPreset falls back to Normal for an unknown difficulty.
+func (w *World) difficultyPreset() difficultyPreset {
+	if preset, ok := difficultyPresets[w.Difficulty]; ok {
+		return preset
+	}
+	return difficultyPresets[DifficultyNormal]
+}
+
+// EnemySpeed is the horizontal speed of new ebis.
+func (w *World) EnemySpeed() float64 {
+	return w.Tuning.enemySpeed(w.Level()) * w.difficultyPreset().enemySpeed * w.directorAdjustment()
+}
+
+// UFOSpeed is the horizontal speed of new UFOs.
+func (w *World) UFOSpeed() float64 {
+	if w.Modifier == ModifierFastUFOs {
+		return 2 * w.EnemySpeed()
+	}
+	return w.EnemySpeed()
+}
+
+// FallingSpeed is how far the bashiHebis fall each tick.
+func (w *World) FallingSpeed() float64 {
+	return w.Tuning.fallingSpeed(w.Level()) * w.difficultyPreset().fallingSpeed * w.directorAdjustment()
+}
+
+func (w *World) bossHealth() int {
+	return max(1, int(math.Round(float64(w.Tuning.bossHealth(w.Wave))*w.difficultyPreset().bossHP)))
+}
+
+func (w *World) bossAttackTime() int {
+	return max(1, int(math.Round(float64(w.Tuning.BossAttackTime)*w.difficultyPreset().bossAttackTime)))
+}
+
+func (w *World) powerUpDropRate() int {
+	return max(1, int(math.Round(float64(w.Tuning.PowerUpDropRate)*w.difficultyPreset().powerUpDropRate)))
+}
+
+// spawnOdds scales the "one in odds" denominator of a spawn roll, so higher
+// spawn rates make rolls succeed more often.
+func (w *World) spawnOdds(odds int) int {
+	return max(1, int(math.Round(float64(odds)/(w.difficultyPreset().spawnRate*w.directorAdjustment()))))
+}
+
+// SpecialCost is the KIEE charge the special attack needs.
+func (w *World) SpecialCost() int {
+	if w.Modifier == ModifierCheapKIEE {
+		return SpecialCost / 2
+	}
+	return SpecialCost
+}
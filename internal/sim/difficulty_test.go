@@ -0,0 +1,46 @@
+package sim
+
+import "testing"
+
+func TestNormalDifficultyKeepsWaveCurve(t *testing.T) {
+	w := testWorld()
+	w.Wave = 4
+	if w.EnemySpeed() != w.Tuning.enemySpeed(4) || w.FallingSpeed() != w.Tuning.fallingSpeed(4) {
+		t.Fatal("normal difficulty changed enemy speeds")
+	}
+	if w.bossHealth() != w.Tuning.bossHealth(4) || w.bossAttackTime() != bossAttackTime || w.powerUpDropRate() != powerUpDropRate {
+		t.Fatal("normal difficulty changed boss or power-up tuning")
+	}
+	if w.spawnOdds(120) != 120 {
+		t.Fatalf("spawnOdds(120) = %d, want 120", w.spawnOdds(120))
+	}
+}
+
+func TestHarderDifficultiesScaleUp(t *testing.T) {
+	previous := testWorld()
+	previous.Wave = 5
+	previous.Difficulty = DifficultyEasy
+	for _, level := range Difficulties[1:] {
+		w := testWorld()
+		w.Wave = 5
+		w.Difficulty = level
+		if w.EnemySpeed() <= previous.EnemySpeed() || w.FallingSpeed() <= previous.FallingSpeed() {
+			t.Errorf("%q enemies are not faster than %q", level, previous.Difficulty)
+		}
+		if w.bossHealth() <= previous.bossHealth() || w.spawnOdds(120) >= previous.spawnOdds(120) {
+			t.Errorf("%q boss or spawns are not harder than %q", level, previous.Difficulty)
+		}
+		if w.bossAttackTime() >= previous.bossAttackTime() || w.powerUpDropRate() <= previous.powerUpDropRate() {
+			t.Errorf("%q boss attacks or power-ups are not harder than %q", level, previous.Difficulty)
+		}
+		previous = w
+	}
+}
+
+func TestUnknownDifficultyPlaysNormal(t *testing.T) {
+	w := testWorld()
+	w.Difficulty = "impossible"
+	if KnownDifficulty(w.Difficulty) || w.EnemySpeed() != w.Tuning.enemySpeed(1) {
+		t.Fatal("an unknown difficulty did not fall back to normal")
+	}
+}
@@ -0,0 +1,140 @@
+package sim
+
+import (
+	"image"
+	"log"
+	"strings"
+)
+
+const (
+	directorWindow       = 10 * 60 // Ticks of play between evaluations.
+	directorStep         = 0.05
+	maxDirectorLevel     = 0.25 // Spawn rates and speeds stay within ±25%.
+	nearMissDistance     = 24   // Pixels around the hitbox that count as a close call.
+	directorGoodAccuracy = 0.7
+	directorPoorAccuracy = 0.3
+	directorGoodCombo    = 15
+	directorNearMisses   = 3
+	directorFastWave     = 20 * 60
+	directorSlowWave     = 60 * 60
+)
+
+// director is the optional adaptive difficulty. It watches a window of recent
+// play and moves its level one step up when the player is doing well or one
+// step down when they are struggling. The level scales spawn rates and enemy
+// speeds on top of the chosen difficulty.
+type director struct {
+	level      float64
+	ticks      int
+	waveTicks  int
+	hits       int
+	misses     int
+	bestCombo  int
+	nearMisses int
+}
+
+// directorAdjustment returns the multiplier for spawn rates and speeds. It is
+// 1 while the director is off.
+func (w *World) directorAdjustment() float64 {
+	if !w.Adaptive {
+		return 1
+	}
+	return 1 + w.director.level
+}
+
+func (w *World) directorRecordHit(combo int) {
+	w.director.hits++
+	w.director.bestCombo = max(w.director.bestCombo, combo)
+}
+
+func (w *World) directorRecordMiss() {
+	w.director.misses++
+}
+
+func (w *World) directorRecordNearMiss() {
+	w.director.nearMisses++
+}
+
+// updateDirector runs once per tick of play and evaluates the window when it
+// fills up.
+func (w *World) updateDirector() {
+	if !w.Adaptive {
+		return
+	}
+	w.director.ticks++
+	w.director.waveTicks++
+	if w.director.ticks >= directorWindow {
+		w.evaluateDirector(0)
+	}
+}
+
+// directorWaveCleared scores how long the wave took, so quick clears push the
+// level up even when the window has not filled yet.
+func (w *World) directorWaveCleared() {
+	if !w.Adaptive {
+		return
+	}
+	waveTicks := w.director.waveTicks
+	w.director.waveTicks = 0
+	switch {
+	case waveTicks > 0 && waveTicks < directorFastWave:
+		w.evaluateDirector(1)
+	case waveTicks > directorSlowWave:
+		w.evaluateDirector(-1)
+	}
+}
+
+// evaluateDirector adds up the signals from the window, moves the level by
+// one step in their direction and starts a new window.
+func (w *World) evaluateDirector(waveScore int) {
+	stats := w.director
+	score := waveScore
+	var reasons []string
+	if waveScore > 0 {
+		reasons = append(reasons, "fast wave")
+	} else if waveScore < 0 {
+		reasons = append(reasons, "slow wave")
+	}
+	if shots := stats.hits + stats.misses; shots > 0 {
+		accuracy := float64(stats.hits) / float64(shots)
+		if accuracy >= directorGoodAccuracy {
+			score++
+			reasons = append(reasons, "accurate")
+		} else if accuracy <= directorPoorAccuracy {
+			score--
+			reasons = append(reasons, "missing")
+		}
+	}
+	if stats.bestCombo >= directorGoodCombo {
+		score++
+		reasons = append(reasons, "long combo")
+	}
+	if stats.nearMisses >= directorNearMisses {
+		score--
+		reasons = append(reasons, "near misses")
+	}
+
+	level := stats.level
+	switch {
+	case score > 0:
+		level = min(maxDirectorLevel, level+directorStep)
+	case score < 0:
+		level = max(-maxDirectorLevel, level-directorStep)
+	}
+	if w.Debug {
+		log.Printf("debug: director level %.2f -> %.2f (hits=%d misses=%d bestCombo=%d nearMisses=%d signals=%s)",
+			stats.level, level, stats.hits, stats.misses, stats.bestCombo, stats.nearMisses, strings.Join(reasons, ","))
+	}
+	w.director = director{level: level, waveTicks: stats.waveTicks}
+}
+
+// checkNearMiss counts a falling enemy whose bottom edge passes the top of
+// the player's hitbox this tick close beside it.
+func (w *World) checkNearMiss(enemyRect, playerRect image.Rectangle, speed float64) {
+	if enemyRect.Max.Y < playerRect.Min.Y || float64(enemyRect.Max.Y)-speed >= float64(playerRect.Min.Y) {
+		return
+	}
+	if enemyRect.Overlaps(playerRect.Inset(-nearMissDistance)) {
+		w.directorRecordNearMiss()
+	}
+}
@@ -0,0 +1,85 @@
+package sim
+
+import (
+	"image"
+	"testing"
+)
+
+func TestDirectorOffLeavesDifficultyAlone(t *testing.T) {
+	w := testWorld()
+	w.Wave = 3
+	w.director.level = maxDirectorLevel
+	if w.EnemySpeed() != w.Tuning.enemySpeed(3) || w.spawnOdds(120) != 120 {
+		t.Fatal("director changed the game while adaptive difficulty was off")
+	}
+	for range directorWindow {
+		w.updateDirector()
+	}
+	if w.director.ticks != 0 {
+		t.Fatal("director ran while adaptive difficulty was off")
+	}
+}
+
+func TestDirectorRaisesLevelForStrongPlay(t *testing.T) {
+	w := testWorld()
+	w.Wave = 3
+	w.Adaptive = true
+	for range 20 {
+		w.recordHit(PlayerOne, 1)
+	}
+	for range directorWindow {
+		w.updateDirector()
+	}
+	if w.director.level != directorStep {
+		t.Fatalf("level = %.2f, want %.2f", w.director.level, directorStep)
+	}
+	if w.director.hits != 0 || w.director.ticks != 0 {
+		t.Fatalf("director = %+v, want a fresh window", w.director)
+	}
+	if w.EnemySpeed() <= w.Tuning.enemySpeed(3) || w.spawnOdds(120) >= 120 {
+		t.Fatal("a raised level did not speed up enemies or spawns")
+	}
+}
+
+func TestDirectorLowersLevelWithinBounds(t *testing.T) {
+	w := testWorld()
+	w.Adaptive = true
+	for range 20 {
+		w.directorRecordMiss()
+		for range directorNearMisses {
+			w.directorRecordNearMiss()
+		}
+		w.evaluateDirector(-1)
+	}
+	if w.director.level != -maxDirectorLevel {
+		t.Fatalf("level = %.2f, want it floored at %.2f", w.director.level, -maxDirectorLevel)
+	}
+}
+
+func TestDirectorScoresWaveClearTime(t *testing.T) {
+	w := testWorld()
+	w.Adaptive = true
+	w.director.waveTicks = directorFastWave / 2
+	w.directorWaveCleared()
+	if w.director.level != directorStep || w.director.waveTicks != 0 {
+		t.Fatalf("director = %+v, want a quick clear to raise the level", w.director)
+	}
+}
+
+func TestCheckNearMissCountsCloseCallsOnce(t *testing.T) {
+	w := testWorld()
+	player := image.Rect(100, 300, 140, 400)
+	speed := 4.0
+	for y := 250; y < 320; y += int(speed) {
+		w.checkNearMiss(image.Rect(150, y, 170, y+20), player, speed)
+	}
+	if w.director.nearMisses != 1 {
+		t.Fatalf("near misses = %d, want 1", w.director.nearMisses)
+	}
+	for y := 250; y < 320; y += int(speed) {
+		w.checkNearMiss(image.Rect(300, y, 320, y+20), player, speed)
+	}
+	if w.director.nearMisses != 1 {
+		t.Fatalf("near misses = %d, want far enemies ignored", w.director.nearMisses)
+	}
+}
@@ -0,0 +1,100 @@
+package sim
+
+// Event is something that happened during a tick. The simulation only emits
+// events; the game plays sounds, shows effects and keeps stats from them,
+// and tools count them.
+type Event interface {
+	event()
+}
+
+// ShotFired is a player shot, with Count projectiles while powered up.
+type ShotFired struct {
+	Shooter int
+	Count   int
+}
+
+// UFODestroyed is a UFO shot down or wiped out by the special attack.
+type UFODestroyed struct {
+	Shooter int
+	Special bool
+}
+
+type EbiHit struct {
+	Shooter int
+}
+
+// EbiSpawned is an ebi entering the screen.
+type EbiSpawned struct{}
+
+// BossDamaged is a projectile hit on the boss or the special attack's damage.
+type BossDamaged struct {
+	Shooter int
+	Damage  int
+	Special bool
+}
+
+type BossDefeated struct {
+	Shooter int
+}
+
+// BossAttacked is the boss dropping a bashiHebi.
+type BossAttacked struct{}
+
+// WaveCleared is sent for the wave that was won, before the next one starts.
+type WaveCleared struct {
+	Wave int
+}
+
+type WaveStarted struct {
+	Wave int
+}
+
+type PowerUpCollected struct {
+	Player int
+}
+
+// PlayerDied is a player touching an enemy. Down is set when a co-op partner
+// is still standing and the player waits for a revive.
+type PlayerDied struct {
+	Player int
+	Enemy  Point
+	Down   bool
+}
+
+type PlayerRevived struct {
+	Player int
+}
+
+type SpecialUsed struct {
+	Shooter int
+}
+
+// TimeUp is the end of a score attack run.
+type TimeUp struct{}
+
+// SurvivalRamped is survival stepping its enemies up to Level.
+type SurvivalRamped struct {
+	Level int
+}
+
+func (ShotFired) event()        {}
+func (UFODestroyed) event()     {}
+func (EbiHit) event()           {}
+func (EbiSpawned) event()       {}
+func (BossDamaged) event()      {}
+func (BossDefeated) event()     {}
+func (BossAttacked) event()     {}
+func (WaveCleared) event()      {}
+func (WaveStarted) event()      {}
+func (PowerUpCollected) event() {}
+func (PlayerDied) event()       {}
+func (PlayerRevived) event()    {}
+func (SpecialUsed) event()      {}
+func (TimeUp) event()           {}
+func (SurvivalRamped) event()   {}
+
+func (w *World) emit(event Event) {
+	if w.OnEvent != nil {
+		w.OnEvent(event)
+	}
+}
@@ -0,0 +1,83 @@
+package sim
+
+import "log"
+
+// Mode picks how a run progresses. The empty mode is the original wave
+// progression.
+type Mode string
+
+const (
+	ModeWaves       Mode = ""
+	ModeScoreAttack Mode = "scoreAttack"
+	ModeSurvival    Mode = "survival"
+	ModeBossRush    Mode = "bossRush"
+)
+
+// Modes lists the modes the simulation knows.
+var Modes = []Mode{ModeWaves, ModeScoreAttack, ModeSurvival, ModeBossRush}
+
+// Modifier is the twist of a daily challenge. The empty modifier changes
+// nothing.
+type Modifier string
+
+const (
+	ModifierFastUFOs   Modifier = "fastUFOs"
+	ModifierNoPowerUps Modifier = "noPowerUps"
+	ModifierCheapKIEE  Modifier = "cheapKIEE"
+)
+
+const (
+	ScoreAttackTime  = 2 * 60 * 60 // Ticks in a score attack run.
+	survivalRampTime = 20 * 60     // Ticks between difficulty steps in survival.
+)
+
+// nextWave is the wave that follows a cleared one. Boss rush skips straight
+// to the next boss.
+func (w *World) nextWave() int {
+	if w.Mode == ModeBossRush {
+		return NextBossWave(w.Wave + 1)
+	}
+	return w.Wave + 1
+}
+
+// NextBossWave is the first boss wave from wave on.
+func NextBossWave(wave int) int {
+	if IsBossWave(wave) {
+		return wave
+	}
+	return (wave/BossWaveCycle + 1) * BossWaveCycle
+}
+
+// wavesClear reports whether killing enough UFOs ends a wave. Survival keeps
+// one long wave whose difficulty ramps with time instead.
+func (w *World) wavesClear() bool {
+	return w.Mode != ModeSurvival
+}
+
+// Level is the wave the enemies scale with: the wave itself, plus the steps
+// survival has ramped. Survival stays in its first wave, so the boss waves,
+// banners and wave records never see the ramp.
+func (w *World) Level() int {
+	return w.Wave + w.SurvivalLevel
+}
+
+// updateMode runs the score attack countdown and the survival ramp.
+func (w *World) updateMode() {
+	switch w.Mode {
+	case ModeScoreAttack:
+		w.ModeTicks--
+		if w.ModeTicks <= 0 && !w.Over {
+			w.emit(TimeUp{})
+			w.end()
+		}
+	case ModeSurvival:
+		w.ModeTicks++
+		if w.ModeTicks%survivalRampTime == 0 {
+			w.SurvivalLevel++
+			w.emit(SurvivalRamped{Level: w.Level()})
+			if w.Debug {
+				log.Printf("debug: survival ramped to level %d after %d ticks", w.Level(), w.ModeTicks)
+			}
+		}
+	}
+}
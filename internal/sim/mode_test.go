@@ -0,0 +1,86 @@
+package sim
+
+import "testing"
+
+func TestScoreAttackEndsWhenTimeRunsOut(t *testing.T) {
+	w := New(Config{Tuning: DefaultTuning(), Mode: ModeScoreAttack}, Sizes{})
+	w.Pilots[PlayerOne].Combo = 4
+	events := recordEvents(w)
+	if w.ModeTicks != ScoreAttackTime {
+		t.Fatalf("ModeTicks = %d, want %d", w.ModeTicks, ScoreAttackTime)
+	}
+	for range ScoreAttackTime - 1 {
+		w.updateMode()
+	}
+	if w.Over {
+		t.Fatal("score attack ended before the timer ran out")
+	}
+	w.updateMode()
+	if !w.Over || w.Pilots[PlayerOne].Combo != 0 || len(*events) != 1 || (*events)[0] != (TimeUp{}) {
+		t.Fatalf("over = %v combo = %d events = %v, want a time-up", w.Over, w.Pilots[PlayerOne].Combo, *events)
+	}
+}
+
+func TestSurvivalRampsWithoutClearingWaves(t *testing.T) {
+	w := testWorld()
+	w.Mode = ModeSurvival
+	w.UFOKills = ufoMaxTarget
+	if w.recordUFODefeat(PlayerOne) {
+		t.Fatal("survival cleared a wave")
+	}
+	events := recordEvents(w)
+	speed := w.FallingSpeed()
+	for range BossWaveCycle * survivalRampTime {
+		w.updateMode()
+	}
+	if w.Wave != 1 || w.Level() != 1+BossWaveCycle || w.FallingSpeed() <= speed {
+		t.Fatalf("wave = %d level = %d, want the first wave with the difficulty ramped to %d", w.Wave, w.Level(), 1+BossWaveCycle)
+	}
+	if len(*events) != BossWaveCycle || (*events)[0] != (SurvivalRamped{Level: 2}) {
+		t.Fatalf("events = %v, want one SurvivalRamped per step", *events)
+	}
+}
+
+func TestBossRushChainsBossWaves(t *testing.T) {
+	w := New(Config{Tuning: DefaultTuning(), Mode: ModeBossRush}, Sizes{})
+	if w.Wave != BossWaveCycle || w.Boss == nil {
+		t.Fatalf("wave = %d boss = %v, want boss rush to open on the first boss", w.Wave, w.Boss)
+	}
+	if next := w.nextWave(); next != 2*BossWaveCycle {
+		t.Fatalf("next wave = %d, want %d", next, 2*BossWaveCycle)
+	}
+	w.Mode = ModeWaves
+	if next := w.nextWave(); next != BossWaveCycle+1 {
+		t.Fatalf("next wave = %d, want %d", next, BossWaveCycle+1)
+	}
+}
+
+func TestNextBossWave(t *testing.T) {
+	for wave, want := range map[int]int{1: BossWaveCycle, BossWaveCycle: BossWaveCycle, BossWaveCycle + 1: 2 * BossWaveCycle} {
+		if got := NextBossWave(wave); got != want {
+			t.Errorf("NextBossWave(%d) = %d, want %d", wave, got, want)
+		}
+	}
+}
+
+func TestDailyModifiers(t *testing.T) {
+	w := testWorld()
+	if w.SpecialCost() != SpecialCost {
+		t.Fatal("special cost changed without a modifier")
+	}
+	w.Modifier = ModifierCheapKIEE
+	if w.SpecialCost() != SpecialCost/2 {
+		t.Fatalf("special cost = %d, want %d", w.SpecialCost(), SpecialCost/2)
+	}
+	w.Modifier = ModifierFastUFOs
+	if w.UFOSpeed() != 2*w.EnemySpeed() {
+		t.Fatalf("UFO speed = %.2f, want double %.2f", w.UFOSpeed(), w.EnemySpeed())
+	}
+	w.Modifier = ModifierNoPowerUps
+	for range 100 {
+		w.maybeDropPowerUp(Point{})
+	}
+	if len(w.PowerUps) != 0 {
+		t.Fatal("power-up dropped with the no power-ups modifier")
+	}
+}
@@ -0,0 +1,151 @@
+package sim
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	PlayerOne = iota
+	PlayerTwo
+)
+
+const (
+	ReviveTime     = 2 * 60 // Ticks a partner has to stay close to revive a downed player.
+	reviveDistance = 70     // Pixels between the players' centers that count as close.
+	hitboxPadding  = 30     // Pixels trimmed from each side of the player sprite for collisions.
+)
+
+// The raised fingertip is about 11% of the way across ebisan.png.
+const playerFingerTipXRatio = 0.11
+
+// Pilot is one player's ship and the stats that belong to them.
+type Pilot struct {
+	Position     Point
+	Combo        int
+	MissCount    int // KIEE charge: shots that left the screen.
+	ShotCooldown int
+	Points       int  // Score earned by this player, shown with split scores.
+	Down         bool // Knocked out in co-op until the partner revives them.
+	ReviveTicks  int
+}
+
+// shouldFire fires when fire is first pressed and then every interval ticks
+// while it is held.
+func (p *Pilot) shouldFire(firePressed bool, interval int) bool {
+	if !firePressed {
+		p.ShotCooldown = 0
+		return false
+	}
+	if p.ShotCooldown > 0 {
+		p.ShotCooldown--
+		return false
+	}
+	p.ShotCooldown = interval - 1
+	return true
+}
+
+func (p *Pilot) ComboMultiplier() int {
+	return min(MaxComboBonus, 1+p.Combo/ComboStep)
+}
+
+// Controls is one tick of a player's input. Drag and Tap come from touch:
+// the pixels the finger moved and a tap that shoots once.
+type Controls struct {
+	Left    bool
+	Right   bool
+	Fire    bool
+	Special bool
+	Drag    float64
+	Tap     bool
+}
+
+func (controls Controls) Or(other Controls) Controls {
+	return Controls{
+		Left:    controls.Left || other.Left,
+		Right:   controls.Right || other.Right,
+		Fire:    controls.Fire || other.Fire,
+		Special: controls.Special || other.Special,
+		Drag:    controls.Drag + other.Drag,
+		Tap:     controls.Tap || other.Tap,
+	}
+}
+
+func (w *World) PilotCount() int {
+	if w.Coop {
+		return 2
+	}
+	return 1
+}
+
+// PilotRect is a player's hitbox, trimmed inside the sprite.
+func (w *World) PilotRect(index int) image.Rectangle {
+	p := w.Pilots[index]
+	padding := hitboxPadding + w.HitboxPadding
+	return image.Rect(
+		int(p.Position.X)+padding,
+		int(p.Position.Y)+padding,
+		int(p.Position.X)+int(w.Sizes.PilotWidth())-padding,
+		int(p.Position.Y)+int(w.Sizes.PilotHeight())-padding,
+	)
+}
+
+// ShotX is where a player's shots leave the raised fingertip.
+func (w *World) ShotX(index int) float64 {
+	return w.Pilots[index].Position.X + float64(w.Sizes.PilotWidth()*playerFingerTipXRatio) - float64(w.Sizes.Projectile.X)/2
+}
+
+// placePilots lines the players up along the bottom: centered alone, or at
+// the thirds of the screen in co-op.
+func (w *World) placePilots() {
+	width := w.Sizes.PilotWidth()
+	y := float64(ScreenHeight) - w.Sizes.PilotHeight()
+	for index := range w.PilotCount() {
+		center := float64(ScreenWidth) * float64(index+1) / float64(w.PilotCount()+1)
+		w.Pilots[index].Position = Point{X: center - width/2, Y: y}
+	}
+}
+
+// MovePilot moves a player sideways, keeping them on the screen.
+func (w *World) MovePilot(index int, distance float64) {
+	p := &w.Pilots[index]
+	p.Position.X = min(float64(ScreenWidth)-w.Sizes.PilotWidth(), max(0, p.Position.X+distance))
+}
+
+// knockDown handles a player touching an enemy. In co-op the player goes down
+// and waits for a revive; the run ends only when nobody is left standing. In
+// versus there are no revives and a downed player is out.
+func (w *World) knockDown(index int) {
+	p := &w.Pilots[index]
+	p.Combo = 0
+	if !w.Coop || w.Pilots[1-index].Down {
+		w.end()
+		return
+	}
+	p.Down = true
+	p.ReviveTicks = 0
+}
+
+// updateRevives counts up while a standing partner stays next to a downed
+// player and brings them back once ReviveTime is reached.
+func (w *World) updateRevives() {
+	if !w.Coop || w.Versus {
+		return
+	}
+	for index := range w.PilotCount() {
+		p, partner := &w.Pilots[index], &w.Pilots[1-index]
+		if !p.Down {
+			continue
+		}
+		if partner.Down || math.Abs(p.Position.X-partner.Position.X) > reviveDistance {
+			p.ReviveTicks = 0
+			continue
+		}
+		p.ReviveTicks++
+		if p.ReviveTicks >= ReviveTime {
+			p.Down = false
+			p.ReviveTicks = 0
+			w.emit(PlayerRevived{Player: index})
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package sim
+
+import "testing"
+
+func TestShouldFireWhileFireIsHeld(t *testing.T) {
+	var p Pilot
+	firedAt := make([]int, 0, 3)
+
+	for tick := 0; tick < shotInterval*3; tick++ {
+		if p.shouldFire(true, shotInterval) {
+			firedAt = append(firedAt, tick)
+		}
+	}
+
+	want := []int{0, shotInterval, shotInterval * 2}
+	if len(firedAt) != len(want) {
+		t.Fatalf("fired at %v, want %v", firedAt, want)
+	}
+	for i := range want {
+		if firedAt[i] != want[i] {
+			t.Fatalf("fired at %v, want %v", firedAt, want)
+		}
+	}
+}
+
+func TestShouldFireImmediatelyAfterFireIsReleased(t *testing.T) {
+	var p Pilot
+	if !p.shouldFire(true, shotInterval) {
+		t.Fatal("first press did not fire")
+	}
+	if p.shouldFire(true, shotInterval) {
+		t.Fatal("fired again before the interval elapsed")
+	}
+	if p.shouldFire(false, shotInterval) {
+		t.Fatal("fired after fire was released")
+	}
+	if !p.shouldFire(true, shotInterval) {
+		t.Fatal("new press did not fire immediately")
+	}
+}
+
+func TestCoopKnockDownWaitsForPartner(t *testing.T) {
+	w := testWorld()
+	w.Coop = true
+	w.Pilots[PlayerOne].Combo = 7
+	w.knockDown(PlayerOne)
+	if w.Over || !w.Pilots[PlayerOne].Down || w.Pilots[PlayerOne].Combo != 0 {
+		t.Fatalf("over = %v down = %v combo = %d, want player one down and the run going on", w.Over, w.Pilots[PlayerOne].Down, w.Pilots[PlayerOne].Combo)
+	}
+	w.knockDown(PlayerTwo)
+	if !w.Over {
+		t.Fatal("run went on with both players down")
+	}
+}
+
+func TestCoopReviveNeedsPartnerNearby(t *testing.T) {
+	w := testWorld()
+	w.Coop = true
+	events := recordEvents(w)
+	w.Pilots[PlayerOne].Down = true
+	w.Pilots[PlayerTwo].Position.X = reviveDistance + 1
+	w.updateRevives()
+	if w.Pilots[PlayerOne].ReviveTicks != 0 {
+		t.Fatalf("reviveTicks = %d, want 0 while the partner is far away", w.Pilots[PlayerOne].ReviveTicks)
+	}
+	w.Pilots[PlayerTwo].Position.X = reviveDistance
+	for range ReviveTime - 1 {
+		w.updateRevives()
+	}
+	if !w.Pilots[PlayerOne].Down {
+		t.Fatal("player revived before ReviveTime")
+	}
+	w.updateRevives()
+	if w.Pilots[PlayerOne].Down || len(*events) != 1 || (*events)[0] != (PlayerRevived{Player: PlayerOne}) {
+		t.Fatalf("down = %v events = %v, want player one revived next to the partner", w.Pilots[PlayerOne].Down, *events)
+	}
+}
+
+func TestCoopHitsCreditTheShooter(t *testing.T) {
+	w := testWorld()
+	w.Coop = true
+	w.Pilots[PlayerOne].Combo = ComboStep
+	w.recordHit(PlayerTwo, 1)
+	if w.Pilots[PlayerTwo].Combo != 1 || w.Pilots[PlayerOne].Combo != ComboStep {
+		t.Fatalf("combos = %d/%d, want only player two's combo to grow", w.Pilots[PlayerOne].Combo, w.Pilots[PlayerTwo].Combo)
+	}
+	w.recordHit(PlayerOne, 1)
+	if w.Pilots[PlayerOne].Points != 2 || w.Pilots[PlayerTwo].Points != 1 || w.Score != 3 {
+		t.Fatalf("points = %d/%d score = %d, want 2/1 and a team score of 3", w.Pilots[PlayerOne].Points, w.Pilots[PlayerTwo].Points, w.Score)
+	}
+}
+
+func TestDragMovesEvenWhileDown(t *testing.T) {
+	w := New(Config{Tuning: DefaultTuning(), Coop: true}, gameSizes)
+	w.Pilots[PlayerTwo].Down = true
+	start := w.Pilots[PlayerTwo].Position.X
+	w.handlePlayerInput([2]Controls{PlayerTwo: {Drag: 12, Right: true, Fire: true}})
+	if w.Pilots[PlayerTwo].Position.X != start+12 || len(w.Projectiles) != 0 {
+		t.Fatalf("x = %.1f shots = %d, want only the drag applied to a downed player", w.Pilots[PlayerTwo].Position.X, len(w.Projectiles))
+	}
+}
+
+func TestCoopSpecialFallsToTheChargedPartner(t *testing.T) {
+	w := testWorld()
+	w.Coop = true
+	w.Pilots[PlayerTwo].MissCount = SpecialCost
+	events := recordEvents(w)
+	w.handleSpecialAttack([2]Controls{{Special: true}, {Special: true}})
+	if len(*events) == 0 || (*events)[0] != (SpecialUsed{Shooter: PlayerTwo}) || w.Pilots[PlayerTwo].MissCount != 0 {
+		t.Fatalf("events = %v charge = %d, want player two's KIEE fired after player one lacked charge", *events, w.Pilots[PlayerTwo].MissCount)
+	}
+}
@@ -0,0 +1,692 @@
+// Package sim holds the rules of the game: the waves, enemies, shots,
+// scoring and co-op. It does not draw, play sounds or read input, so the
+// game, the headless tools in cmd and the tests all run the same
+// simulation. The game follows what happens through events.
+//
+// Online play needs every machine to compute the same floats. Go may fuse
+// x*y + z into one multiply-add on arm64 but not on amd64 or wasm, and the
+// fused result rounds differently, so a product added to something is
+// written float64(x*y) + z: the spec forbids fusing across a conversion.
+package sim
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"log"
+	"math/rand"
+)
+
+const (
+	ScreenWidth      = 640
+	ScreenHeight     = 480
+	PlayerScale      = 0.1
+	BossScale        = 0.22
+	PowerUpSize      = 20
+	PowerUpShotCount = 3
+	SpecialCost      = 20
+	BossWaveCycle    = 5
+	ComboStep        = 5
+	MaxComboBonus    = 5
+	ufoBaseTarget    = 5
+	ufoMaxTarget     = 20
+	bossY            = -18
+	bossMoveMinTime  = 30
+	bossMoveVariance = 90
+	bossHitPadding   = 18
+	bossSpecialHit   = 10
+	bossDefeatBonus  = 25
+)
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+type Projectile struct {
+	Point
+	VelocityX float64
+	VelocityY float64
+	Shooter   int // PlayerOne or PlayerTwo, credited with hits and misses.
+}
+
+type HorizontalEnemy struct {
+	Point
+	VelocityX float64
+}
+
+type UFO struct {
+	HorizontalEnemy
+	Visible bool
+}
+
+type PowerUp struct {
+	Point
+}
+
+type Boss struct {
+	Point
+	HP             int
+	MaxHP          int
+	Direction      float64
+	AttackCooldown int
+	MoveCooldown   int
+}
+
+// Config is the rules of a run. The game fills it in from the settings when
+// a run starts; tools fill it in directly.
+type Config struct {
+	Tuning        Tuning
+	Difficulty    Difficulty
+	Mode          Mode
+	Modifier      Modifier // The daily challenge's twist, if any.
+	Adaptive      bool     // Let the director nudge spawn rates and speeds.
+	Coop          bool
+	Versus        bool // Co-op without revives; the players compete for points.
+	HitboxPadding int  // Extra pixels trimmed from the players' hitboxes.
+	Invincible    bool // Enemies that touch a player vanish instead.
+	Debug         bool // Log spawns and other details.
+	Seed          int64
+	// OnEvent, if set, is called with each event as it happens, while the
+	// world still shows the state it happened in. It is part of the config so
+	// it also hears the boss rush's opening wave, which New starts.
+	OnEvent func(event Event)
+}
+
+// Sizes are the sprite sizes in pixels, unscaled. The hitboxes and spawn
+// positions depend on them, so the game passes in the images it loaded.
+type Sizes struct {
+	Player     image.Point
+	UFO        image.Point
+	Projectile image.Point
+	BashiHebi  image.Point
+	Ebi        image.Point
+	Boss       image.Point
+}
+
+func (sizes Sizes) PilotWidth() float64 {
+	return float64(sizes.Player.X) * PlayerScale
+}
+
+func (sizes Sizes) PilotHeight() float64 {
+	return float64(sizes.Player.Y) * PlayerScale
+}
+
+func (sizes Sizes) BossWidth() float64 {
+	return float64(sizes.Boss.X) * BossScale
+}
+
+func (sizes Sizes) BossHeight() float64 {
+	return float64(sizes.Boss.Y) * BossScale
+}
+
+// World is the state of one run.
+type World struct {
+	Config
+	Sizes Sizes
+
+	Pilots        [2]Pilot
+	Projectiles   []Projectile
+	UFOs          []UFO
+	BashiHebis    []Point
+	Ebis          []HorizontalEnemy
+	PowerUps      []PowerUp
+	Boss          *Boss
+	Score         int
+	PowerUpTicks  int
+	Wave          int
+	UFOKills      int
+	ModeTicks     int // The score attack countdown or the time survived.
+	SurvivalLevel int // Steps survival has ramped; see Level.
+	Over          bool
+
+	director director
+	random   *rand.Rand
+}
+
+// New starts a run at its first wave.
+func New(config Config, sizes Sizes) *World {
+	w := &World{
+		Config: config,
+		Sizes:  sizes,
+		Wave:   1,
+		random: rand.New(rand.NewSource(config.Seed)),
+	}
+	w.placePilots()
+	switch config.Mode {
+	case ModeScoreAttack:
+		w.ModeTicks = ScoreAttackTime
+	case ModeBossRush:
+		w.StartWave(BossWaveCycle)
+	}
+	return w
+}
+
+// Step simulates one tick with each player's controls.
+func (w *World) Step(controls [2]Controls) {
+	if w.Over {
+		return
+	}
+	w.handlePlayerInput(controls)
+	w.handleProjectileCollisions()
+	w.handleSpecialAttack(controls)
+	w.spawnEnemies()
+	w.moveEntities()
+	w.handlePowerUpCollisions()
+	w.handlePlayerCollision()
+	w.removeOffscreenEntities()
+	w.updateRevives()
+	w.updateDirector()
+	w.updateMode()
+}
+
+// end finishes the run.
+func (w *World) end() {
+	w.Over = true
+	w.Pilots[PlayerOne].Combo = 0
+	w.Pilots[PlayerTwo].Combo = 0
+}
+
+// Hash sums up everything the simulation depends on, so two machines running
+// the same game can check that they agree.
+func (w *World) Hash() uint64 {
+	hash := fnv.New64a()
+	fmt.Fprint(hash, w.Over, w.Wave, w.SurvivalLevel, w.UFOKills, w.Score, w.PowerUpTicks, w.ModeTicks, w.Pilots,
+		w.Projectiles, w.UFOs, w.BashiHebis, w.Ebis, w.PowerUps)
+	if w.Boss != nil {
+		fmt.Fprint(hash, *w.Boss)
+	}
+	return hash.Sum64()
+}
+
+func IsBossWave(wave int) bool {
+	return wave > 0 && wave%BossWaveCycle == 0
+}
+
+// UFOTarget is the number of UFOs that clears a wave, or 0 on boss waves.
+func UFOTarget(wave int) int {
+	if IsBossWave(wave) {
+		return 0
+	}
+	return min(ufoMaxTarget, ufoBaseTarget+max(0, wave-1))
+}
+
+func newHorizontalEnemy(imageWidth int, y, speed float64, fromLeft bool) HorizontalEnemy {
+	if fromLeft {
+		return HorizontalEnemy{Point: Point{X: -float64(imageWidth), Y: y}, VelocityX: speed}
+	}
+	return HorizontalEnemy{Point: Point{X: ScreenWidth, Y: y}, VelocityX: -speed}
+}
+
+func horizontalEnemyOffscreen(enemy HorizontalEnemy, imageWidth int) bool {
+	return enemy.X+float64(imageWidth) < 0 || enemy.X > ScreenWidth
+}
+
+func horizontalSpawnSide(velocity float64) string {
+	if velocity > 0 {
+		return "left"
+	}
+	return "right"
+}
+
+func horizontalMovementDirection(velocity float64) string {
+	if velocity > 0 {
+		return "right"
+	}
+	return "left"
+}
+
+func randomHorizontalDirection(random *rand.Rand) float64 {
+	if random.Intn(2) == 0 {
+		return -1
+	}
+	return 1
+}
+
+func projectileOffscreen(projectile Projectile, width, height int) bool {
+	return projectile.Y+float64(height) < 0 ||
+		projectile.X+float64(width) < 0 ||
+		projectile.X > ScreenWidth
+}
+
+// rectAt is the rectangle of a sprite of size drawn at position.
+func rectAt(size image.Point, position Point) image.Rectangle {
+	return image.Rectangle{Max: size}.Add(image.Pt(int(position.X), int(position.Y)))
+}
+
+func (w *World) randomBossMoveTime() int {
+	return bossMoveMinTime + w.random.Intn(bossMoveVariance+1)
+}
+
+// StartWave moves to wave, clearing the enemies and shots but keeping the
+// power-ups.
+func (w *World) StartWave(wave int) {
+	w.Wave = wave
+	w.UFOKills = 0
+	w.Projectiles = nil
+	w.UFOs = nil
+	w.BashiHebis = nil
+	w.Ebis = nil
+	w.Boss = nil
+	w.directorWaveCleared()
+	w.emit(WaveStarted{Wave: wave})
+
+	if !IsBossWave(wave) {
+		return
+	}
+
+	hp := w.bossHealth()
+	w.Boss = &Boss{
+		Point:          Point{X: (ScreenWidth - w.Sizes.BossWidth()) / 2, Y: bossY},
+		HP:             hp,
+		MaxHP:          hp,
+		Direction:      randomHorizontalDirection(w.random),
+		AttackCooldown: w.bossAttackTime(),
+		MoveCooldown:   w.randomBossMoveTime(),
+	}
+}
+
+// clearWave moves on to the next wave after the current one was won.
+func (w *World) clearWave() {
+	w.emit(WaveCleared{Wave: w.Wave})
+	w.StartWave(w.nextWave())
+}
+
+// finishBossWave pays the boss bonus to the player who landed the last hit.
+func (w *World) finishBossWave(shooter int) {
+	w.scoreFor(shooter, bossDefeatBonus*w.Pilots[shooter].ComboMultiplier())
+	w.emit(BossDefeated{Shooter: shooter})
+	w.clearWave()
+}
+
+func (w *World) handlePlayerInput(controls [2]Controls) {
+	for index := range w.PilotCount() {
+		p := &w.Pilots[index]
+		w.MovePilot(index, controls[index].Drag)
+		if p.Down {
+			p.shouldFire(false, w.Tuning.ShotInterval)
+			continue
+		}
+		if controls[index].Left {
+			w.MovePilot(index, -w.Tuning.PlayerSpeed)
+		}
+		if controls[index].Right {
+			w.MovePilot(index, w.Tuning.PlayerSpeed)
+		}
+		fire := p.shouldFire(controls[index].Fire, w.Tuning.ShotInterval)
+		if fire || controls[index].Tap {
+			w.fireProjectiles(index, w.ShotX(index), p.Position.Y)
+		}
+	}
+}
+
+func (w *World) fireProjectiles(shooter int, x, y float64) {
+	w.Projectiles = append(w.Projectiles, Projectile{
+		Point:     Point{X: x, Y: y},
+		VelocityY: -w.Tuning.ProjectileSpeed,
+		Shooter:   shooter,
+	})
+	if w.PowerUpTicks <= 0 {
+		w.emit(ShotFired{Shooter: shooter, Count: 1})
+		return
+	}
+	diagonal := w.Tuning.PowerUpDiagonalSpeed
+	w.Projectiles = append(w.Projectiles,
+		Projectile{Point: Point{X: x, Y: y}, VelocityX: -diagonal, VelocityY: -diagonal, Shooter: shooter},
+		Projectile{Point: Point{X: x, Y: y}, VelocityX: diagonal, VelocityY: -diagonal, Shooter: shooter},
+	)
+	w.emit(ShotFired{Shooter: shooter, Count: PowerUpShotCount})
+}
+
+// recordHit extends the shooter's combo and credits them with the points.
+func (w *World) recordHit(shooter, baseScore int) {
+	p := &w.Pilots[shooter]
+	p.Combo++
+	w.directorRecordHit(p.Combo)
+	w.scoreFor(shooter, baseScore*p.ComboMultiplier())
+}
+
+// scoreFor adds points to the team score and to the player's own share.
+func (w *World) scoreFor(shooter, points int) {
+	p := &w.Pilots[shooter]
+	p.Points = max(0, p.Points+points)
+	w.Score = max(0, w.Score+points)
+}
+
+func (w *World) recordUFODefeat(shooter int) bool {
+	w.recordHit(shooter, 1)
+	w.UFOKills++
+	target := UFOTarget(w.Wave)
+	return w.wavesClear() && target > 0 && w.UFOKills >= target
+}
+
+func (w *World) handleProjectileCollisions() {
+	for projectileIndex := len(w.Projectiles) - 1; projectileIndex >= 0; projectileIndex-- {
+		projectile := w.Projectiles[projectileIndex]
+		projectileRect := rectAt(w.Sizes.Projectile, projectile.Point)
+		hit := false
+		bossDefeated := false
+		waveComplete := false
+
+		if w.Boss != nil && projectileRect.Overlaps(w.BossRect()) {
+			w.Boss.HP--
+			w.recordHit(projectile.Shooter, 1)
+			w.emit(BossDamaged{Shooter: projectile.Shooter, Damage: 1})
+			hit = true
+			bossDefeated = w.Boss.HP <= 0
+		}
+
+		for ufoIndex := range w.UFOs {
+			if hit {
+				break
+			}
+			target := &w.UFOs[ufoIndex]
+			if !target.Visible {
+				continue
+			}
+			if projectileRect.Overlaps(rectAt(w.Sizes.UFO, target.Point)) {
+				dropPosition := Point{
+					X: target.X + float64(w.Sizes.UFO.X-PowerUpSize)/2,
+					Y: target.Y + float64(w.Sizes.UFO.Y-PowerUpSize)/2,
+				}
+				target.Visible = false
+				w.maybeDropPowerUp(dropPosition)
+				waveComplete = w.recordUFODefeat(projectile.Shooter)
+				w.emit(UFODestroyed{Shooter: projectile.Shooter})
+				hit = true
+				break
+			}
+		}
+
+		if !hit {
+			for ebiIndex := len(w.Ebis) - 1; ebiIndex >= 0; ebiIndex-- {
+				if projectileRect.Overlaps(rectAt(w.Sizes.Ebi, w.Ebis[ebiIndex].Point)) {
+					w.Ebis = removeAt(w.Ebis, ebiIndex)
+					w.scoreFor(projectile.Shooter, -2)
+					w.Pilots[projectile.Shooter].Combo = 0
+					w.emit(EbiHit{Shooter: projectile.Shooter})
+					hit = true
+					break
+				}
+			}
+		}
+
+		if hit {
+			w.Projectiles = removeAt(w.Projectiles, projectileIndex)
+		}
+		if bossDefeated {
+			w.finishBossWave(projectile.Shooter)
+			return
+		}
+		if waveComplete {
+			w.clearWave()
+			return
+		}
+	}
+}
+
+func (w *World) maybeDropPowerUp(position Point) {
+	if w.Modifier == ModifierNoPowerUps || w.random.Intn(w.powerUpDropRate()) != 0 {
+		return
+	}
+	w.PowerUps = append(w.PowerUps, PowerUp{Point: position})
+	if w.Debug {
+		log.Printf("debug: power-up dropped at (%.1f,%.1f)", position.X, position.Y)
+	}
+}
+
+// handleSpecialAttack fires at most one special attack a tick. A press
+// without enough charge leaves the partner's press this tick to go off.
+func (w *World) handleSpecialAttack(controls [2]Controls) {
+	for index := range w.PilotCount() {
+		if controls[index].Special && !w.Pilots[index].Down && w.useSpecialAttack(index) {
+			return
+		}
+	}
+}
+
+// useSpecialAttack reports whether the shooter had the charge to fire.
+func (w *World) useSpecialAttack(shooter int) bool {
+	p := &w.Pilots[shooter]
+	if p.MissCount < w.SpecialCost() {
+		return false
+	}
+
+	p.MissCount -= w.SpecialCost()
+	w.emit(SpecialUsed{Shooter: shooter})
+	waveComplete := false
+	if w.Boss != nil {
+		damage := min(bossSpecialHit, w.Boss.HP)
+		for range damage {
+			w.recordHit(shooter, 1)
+		}
+		w.Boss.HP -= damage
+		w.emit(BossDamaged{Shooter: shooter, Damage: damage, Special: true})
+		if w.Boss.HP <= 0 {
+			w.finishBossWave(shooter)
+		}
+	} else {
+		for _, target := range w.UFOs {
+			if target.Visible && target.X+float64(w.Sizes.UFO.X) >= 0 {
+				waveComplete = w.recordUFODefeat(shooter) || waveComplete
+				w.emit(UFODestroyed{Shooter: shooter, Special: true})
+			}
+		}
+		w.UFOs = nil
+	}
+	w.BashiHebis = nil
+	w.Ebis = nil
+	w.Projectiles = nil
+	if waveComplete {
+		w.clearWave()
+	}
+	return true
+}
+
+func (w *World) spawnEnemies() {
+	if w.Boss != nil {
+		w.Boss.AttackCooldown--
+		if w.Boss.AttackCooldown <= 0 {
+			attackX := w.Boss.X + w.Sizes.BossWidth()/2 - float64(w.Sizes.BashiHebi.X)/2
+			attackY := w.Boss.Y + float64(w.Sizes.BossHeight()*0.7)
+			w.BashiHebis = append(w.BashiHebis, Point{X: attackX, Y: attackY})
+			w.Boss.AttackCooldown = w.bossAttackTime()
+			w.emit(BossAttacked{})
+		}
+		return
+	}
+
+	if w.random.Intn(w.spawnOdds(120)) < w.Tuning.ufoChance(w.Level()) {
+		movement := newHorizontalEnemy(
+			w.Sizes.UFO.X,
+			float64(w.random.Intn(ScreenHeight/2)),
+			w.UFOSpeed(),
+			w.random.Intn(2) == 0,
+		)
+		w.UFOs = append(w.UFOs, UFO{
+			HorizontalEnemy: movement,
+			Visible:         true,
+		})
+		if w.Debug {
+			log.Printf("debug: spawned UFO from %s (wave=%d speed=%.2f)", horizontalSpawnSide(movement.VelocityX), w.Wave, w.UFOSpeed())
+		}
+	}
+
+	fallingEnemyChance := min(7, 1+w.Level()/2)
+	if w.random.Intn(w.spawnOdds(165)) < fallingEnemyChance {
+		w.BashiHebis = append(w.BashiHebis, Point{X: float64(w.random.Intn(ScreenWidth)), Y: 0})
+		if w.Debug {
+			log.Printf("debug: spawned falling enemy (wave=%d speed=%.2f)", w.Wave, w.FallingSpeed())
+		}
+	}
+
+	if w.random.Intn(w.spawnOdds(130)) < 1 {
+		movement := newHorizontalEnemy(
+			w.Sizes.Ebi.X,
+			float64(w.random.Intn(ScreenHeight/2)),
+			w.EnemySpeed(),
+			w.random.Intn(2) == 0,
+		)
+		w.Ebis = append(w.Ebis, movement)
+		w.emit(EbiSpawned{})
+		if w.Debug {
+			log.Printf("debug: spawned shrimp from %s (wave=%d speed=%.2f)", horizontalSpawnSide(movement.VelocityX), w.Wave, w.EnemySpeed())
+		}
+	}
+}
+
+func (w *World) moveEntities() {
+	if w.PowerUpTicks > 0 {
+		w.PowerUpTicks--
+	}
+	if w.Boss != nil {
+		bossWidth := w.Sizes.BossWidth()
+		w.Boss.MoveCooldown--
+		if w.Boss.MoveCooldown <= 0 {
+			w.Boss.Direction = randomHorizontalDirection(w.random)
+			w.Boss.MoveCooldown = w.randomBossMoveTime()
+			if w.Debug {
+				log.Printf("debug: boss moving %s for %d ticks", horizontalMovementDirection(w.Boss.Direction), w.Boss.MoveCooldown)
+			}
+		}
+		w.Boss.X += float64(w.Tuning.BossSpeed * w.Boss.Direction)
+		if w.Boss.X <= 0 {
+			w.Boss.X = 0
+			w.Boss.Direction = 1
+		} else if w.Boss.X+bossWidth >= ScreenWidth {
+			w.Boss.X = ScreenWidth - bossWidth
+			w.Boss.Direction = -1
+		}
+	}
+	for index := range w.UFOs {
+		w.UFOs[index].X += w.UFOs[index].VelocityX
+	}
+	fallingSpeed := w.FallingSpeed()
+	for index := range w.BashiHebis {
+		w.BashiHebis[index].Y += fallingSpeed
+	}
+	for index := range w.Ebis {
+		w.Ebis[index].X += w.Ebis[index].VelocityX
+	}
+	for index := range w.Projectiles {
+		w.Projectiles[index].X += w.Projectiles[index].VelocityX
+		w.Projectiles[index].Y += w.Projectiles[index].VelocityY
+	}
+	for index := range w.PowerUps {
+		w.PowerUps[index].Y += w.Tuning.PowerUpSpeed
+	}
+}
+
+// handlePowerUpCollisions lets either standing player collect a power-up.
+// The 3-way shot is shared by the team.
+func (w *World) handlePowerUpCollisions() {
+	for index := len(w.PowerUps) - 1; index >= 0; index-- {
+		item := w.PowerUps[index]
+		itemRect := rectAt(image.Pt(PowerUpSize, PowerUpSize), item.Point)
+		for player := range w.PilotCount() {
+			if w.Pilots[player].Down || !itemRect.Overlaps(w.PilotRect(player)) {
+				continue
+			}
+			w.PowerUps = removeAt(w.PowerUps, index)
+			w.PowerUpTicks = w.Tuning.PowerUpDuration
+			w.emit(PowerUpCollected{Player: player})
+			break
+		}
+	}
+}
+
+// BossRect is the boss's hitbox, trimmed inside the sprite.
+func (w *World) BossRect() image.Rectangle {
+	if w.Boss == nil {
+		return image.Rectangle{}
+	}
+	width := int(w.Sizes.BossWidth())
+	height := int(w.Sizes.BossHeight())
+	return image.Rect(
+		int(w.Boss.X)+bossHitPadding,
+		int(w.Boss.Y)+bossHitPadding,
+		int(w.Boss.X)+width-bossHitPadding,
+		int(w.Boss.Y)+height-bossHitPadding,
+	)
+}
+
+func (w *World) handlePlayerCollision() {
+	for player := range w.PilotCount() {
+		if w.Pilots[player].Down {
+			continue
+		}
+		if !w.handlePilotCollision(player) {
+			return
+		}
+	}
+}
+
+// handlePilotCollision checks one player against the falling enemies and
+// reports whether the run goes on.
+func (w *World) handlePilotCollision(player int) bool {
+	p := w.Pilots[player]
+	playerRect := w.PilotRect(player)
+	fallingSpeed := w.FallingSpeed()
+
+	for index := len(w.BashiHebis) - 1; index >= 0; index-- {
+		enemy := w.BashiHebis[index]
+		enemyRect := rectAt(w.Sizes.BashiHebi, enemy)
+		if enemyRect.Overlaps(playerRect) {
+			w.BashiHebis = removeAt(w.BashiHebis, index)
+			if w.Invincible {
+				if w.Debug {
+					log.Printf("debug: collision ignored (wave=%d score=%d combo=%d player=(%.1f,%.1f) enemy=(%.1f,%.1f))", w.Wave, w.Score, p.Combo, p.Position.X, p.Position.Y, enemy.X, enemy.Y)
+				}
+				continue
+			}
+			down := w.Coop && !w.Pilots[1-player].Down
+			w.emit(PlayerDied{Player: player, Enemy: enemy, Down: down})
+			w.knockDown(player)
+			return down
+		}
+		w.checkNearMiss(enemyRect, playerRect, fallingSpeed)
+	}
+	return true
+}
+
+func (w *World) removeOffscreenEntities() {
+	for index := len(w.BashiHebis) - 1; index >= 0; index-- {
+		if w.BashiHebis[index].Y > ScreenHeight {
+			w.BashiHebis = removeAt(w.BashiHebis, index)
+		}
+	}
+
+	for index := len(w.Projectiles) - 1; index >= 0; index-- {
+		if projectileOffscreen(w.Projectiles[index], w.Sizes.Projectile.X, w.Sizes.Projectile.Y) {
+			w.Pilots[w.Projectiles[index].Shooter].MissCount++
+			w.directorRecordMiss()
+			w.Projectiles = removeAt(w.Projectiles, index)
+		}
+	}
+
+	for index := len(w.UFOs) - 1; index >= 0; index-- {
+		target := w.UFOs[index]
+		if !target.Visible || horizontalEnemyOffscreen(target.HorizontalEnemy, w.Sizes.UFO.X) {
+			w.UFOs = removeAt(w.UFOs, index)
+		}
+	}
+
+	for index := len(w.Ebis) - 1; index >= 0; index-- {
+		if horizontalEnemyOffscreen(w.Ebis[index], w.Sizes.Ebi.X) {
+			w.Ebis = removeAt(w.Ebis, index)
+		}
+	}
+
+	for index := len(w.PowerUps) - 1; index >= 0; index-- {
+		if w.PowerUps[index].Y > ScreenHeight {
+			w.PowerUps = removeAt(w.PowerUps, index)
+		}
+	}
+}
+
+func removeAt[T any](values []T, index int) []T {
+	copy(values[index:], values[index+1:])
+	var zero T
+	values[len(values)-1] = zero
+	return values[:len(values)-1]
+}
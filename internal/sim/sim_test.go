@@ -0,0 +1,346 @@
+package sim
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// gameSizes are the sizes of the game's sprites.
+var gameSizes = Sizes{
+	Player:     image.Pt(797, 1984),
+	UFO:        image.Pt(39, 31),
+	Projectile: image.Pt(10, 10),
+	BashiHebi:  image.Pt(40, 59),
+	Ebi:        image.Pt(30, 34),
+	Boss:       image.Pt(1254, 1254),
+}
+
+// testWorld is a world with the default tuning and empty sprites.
+func testWorld() *World {
+	return &World{Config: Config{Tuning: DefaultTuning()}, Wave: 1, random: rand.New(rand.NewSource(1))}
+}
+
+func recordEvents(w *World) *[]Event {
+	var events []Event
+	w.OnEvent = func(event Event) { events = append(events, event) }
+	return &events
+}
+
+func TestComboMultiplierAndScore(t *testing.T) {
+	w := testWorld()
+	for range ComboStep {
+		w.recordHit(PlayerOne, 1)
+	}
+
+	p := &w.Pilots[PlayerOne]
+	if p.Combo != ComboStep {
+		t.Fatalf("combo = %d, want %d", p.Combo, ComboStep)
+	}
+	if multiplier := p.ComboMultiplier(); multiplier != 2 {
+		t.Fatalf("multiplier = %d, want 2", multiplier)
+	}
+	if w.Score != 6 {
+		t.Fatalf("score = %d, want 6", w.Score)
+	}
+
+	p.Combo = ComboStep * 20
+	if multiplier := p.ComboMultiplier(); multiplier != MaxComboBonus {
+		t.Fatalf("capped multiplier = %d, want %d", multiplier, MaxComboBonus)
+	}
+}
+
+func TestBossWaveAndHealthScaling(t *testing.T) {
+	for _, wave := range []int{BossWaveCycle, BossWaveCycle * 2, BossWaveCycle * 3} {
+		if !IsBossWave(wave) {
+			t.Fatalf("wave %d should be a boss wave", wave)
+		}
+	}
+	for _, wave := range []int{0, 1, BossWaveCycle - 1, BossWaveCycle + 1} {
+		if IsBossWave(wave) {
+			t.Fatalf("wave %d should not be a boss wave", wave)
+		}
+	}
+
+	tuning := DefaultTuning()
+	if hp := tuning.bossHealth(BossWaveCycle); hp != bossBaseHP {
+		t.Fatalf("first boss HP = %d, want %d", hp, bossBaseHP)
+	}
+	wantSecondBossHP := bossBaseHP + bossHPGrowth
+	if hp := tuning.bossHealth(BossWaveCycle * 2); hp != wantSecondBossHP {
+		t.Fatalf("second boss HP = %d, want %d", hp, wantSecondBossHP)
+	}
+}
+
+func TestHorizontalEnemySpawnsFromBothSides(t *testing.T) {
+	const (
+		imageWidth = 32
+		y          = 80
+		speed      = 3.5
+	)
+
+	fromLeft := newHorizontalEnemy(imageWidth, y, speed, true)
+	if fromLeft.X != -imageWidth || fromLeft.VelocityX != speed {
+		t.Fatalf("left spawn = %+v, want x=%d velocity=%v", fromLeft, -imageWidth, speed)
+	}
+
+	fromRight := newHorizontalEnemy(imageWidth, y, speed, false)
+	if fromRight.X != ScreenWidth || fromRight.VelocityX != -speed {
+		t.Fatalf("right spawn = %+v, want x=%d velocity=%v", fromRight, ScreenWidth, -speed)
+	}
+}
+
+func TestEnemySpeedIncreasesByWaveAndIsCapped(t *testing.T) {
+	tuning := DefaultTuning()
+	if got := tuning.enemySpeed(1); got != enemySpeed {
+		t.Fatalf("wave 1 speed = %v, want %v", got, enemySpeed)
+	}
+	if tuning.enemySpeed(2) <= tuning.enemySpeed(1) {
+		t.Fatal("enemy speed did not increase at wave 2")
+	}
+	if got := tuning.enemySpeed(100); got != maxEnemySpeed {
+		t.Fatalf("capped speed = %v, want %v", got, maxEnemySpeed)
+	}
+}
+
+func TestFallingEnemySpeedIncreasesByWaveAndIsCapped(t *testing.T) {
+	tuning := DefaultTuning()
+	if got := tuning.fallingSpeed(1); got != fallingSpeedBase {
+		t.Fatalf("wave 1 falling speed = %v, want %v", got, fallingSpeedBase)
+	}
+	for wave := 2; wave <= 10; wave++ {
+		if tuning.fallingSpeed(wave) <= tuning.fallingSpeed(wave-1) {
+			t.Fatalf("falling speed did not increase from wave %d to %d", wave-1, wave)
+		}
+	}
+	if got := tuning.fallingSpeed(100); got != maxFallingSpeed {
+		t.Fatalf("capped falling speed = %v, want %v", got, maxFallingSpeed)
+	}
+}
+
+func TestUFOChanceGrowsByWaveAndIsCapped(t *testing.T) {
+	tuning := DefaultTuning()
+	if got := tuning.ufoChance(1); got != ufoChance {
+		t.Fatalf("wave 1 UFO chance = %d, want %d", got, ufoChance)
+	}
+	if got := tuning.ufoChance(100); got != maxUFOChance {
+		t.Fatalf("capped UFO chance = %d, want %d", got, maxUFOChance)
+	}
+}
+
+func TestUFOTargetAndWaveCompletion(t *testing.T) {
+	wants := map[int]int{
+		1:                 5,
+		2:                 6,
+		BossWaveCycle - 1: 8,
+		BossWaveCycle:     0,
+		100:               0,
+		101:               ufoMaxTarget,
+	}
+	for wave, want := range wants {
+		if got := UFOTarget(wave); got != want {
+			t.Fatalf("wave %d UFO target = %d, want %d", wave, got, want)
+		}
+	}
+
+	w := testWorld()
+	for defeated := 1; defeated < ufoBaseTarget; defeated++ {
+		if w.recordUFODefeat(PlayerOne) {
+			t.Fatalf("wave completed after %d UFOs, before target %d", defeated, ufoBaseTarget)
+		}
+	}
+	if !w.recordUFODefeat(PlayerOne) {
+		t.Fatalf("wave did not complete after %d UFOs", ufoBaseTarget)
+	}
+}
+
+func TestPowerUpsRemainWhenStartingNextWave(t *testing.T) {
+	w := testWorld()
+	w.PowerUps = []PowerUp{{Point: Point{X: 12, Y: 34}}}
+	w.PowerUpTicks = 120
+	w.StartWave(2)
+
+	if len(w.PowerUps) != 1 || w.PowerUps[0].X != 12 || w.PowerUps[0].Y != 34 {
+		t.Fatalf("power-ups after wave change = %+v, want one unchanged item", w.PowerUps)
+	}
+	if w.PowerUpTicks != 120 {
+		t.Fatalf("active power-up ticks after wave change = %d, want 120", w.PowerUpTicks)
+	}
+	if w.UFOKills != 0 {
+		t.Fatalf("UFO kills after wave change = %d, want 0", w.UFOKills)
+	}
+}
+
+func TestBossMovementUsesBothDirectionsAndBoundedTiming(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	seenLeft := false
+	seenRight := false
+	for range 100 {
+		direction := randomHorizontalDirection(random)
+		seenLeft = seenLeft || direction < 0
+		seenRight = seenRight || direction > 0
+	}
+	if !seenLeft || !seenRight {
+		t.Fatalf("random boss movement directions: left=%t right=%t", seenLeft, seenRight)
+	}
+
+	w := &World{random: rand.New(rand.NewSource(2))}
+	for range 100 {
+		duration := w.randomBossMoveTime()
+		if duration < bossMoveMinTime || duration > bossMoveMinTime+bossMoveVariance {
+			t.Fatalf("boss movement duration %d is outside the configured range", duration)
+		}
+	}
+}
+
+func TestPowerUpCreatesThreeShotsAndExpires(t *testing.T) {
+	w := testWorld()
+	w.fireProjectiles(PlayerOne, 100, 200)
+	if len(w.Projectiles) != 1 {
+		t.Fatalf("normal shot count = %d, want 1", len(w.Projectiles))
+	}
+
+	w.Projectiles = nil
+	w.PowerUpTicks = powerUpDuration
+	w.fireProjectiles(PlayerOne, 100, 200)
+	if len(w.Projectiles) != PowerUpShotCount {
+		t.Fatalf("powered shot count = %d, want %d", len(w.Projectiles), PowerUpShotCount)
+	}
+	center := w.Projectiles[0]
+	left := w.Projectiles[1]
+	right := w.Projectiles[2]
+	if center.VelocityX != 0 || center.VelocityY != -projectileSpeed {
+		t.Fatalf("center shot velocity = (%.1f, %.1f)", center.VelocityX, center.VelocityY)
+	}
+	if left.VelocityX != -powerUpDiagonalSpeed || left.VelocityY != -powerUpDiagonalSpeed {
+		t.Fatalf("left shot velocity = (%.1f, %.1f)", left.VelocityX, left.VelocityY)
+	}
+	if right.VelocityX != powerUpDiagonalSpeed || right.VelocityY != -powerUpDiagonalSpeed {
+		t.Fatalf("right shot velocity = (%.1f, %.1f)", right.VelocityX, right.VelocityY)
+	}
+	w.moveEntities()
+	if !(left.X > w.Projectiles[1].X && right.X < w.Projectiles[2].X) {
+		t.Fatalf("diagonal shots did not spread: left=%+v right=%+v", w.Projectiles[1], w.Projectiles[2])
+	}
+	w.PowerUpTicks = 1
+	w.moveEntities()
+	if w.PowerUpTicks != 0 {
+		t.Fatalf("expired power-up ticks = %d, want 0", w.PowerUpTicks)
+	}
+}
+
+func TestPowerUpPickupStartsTheTimer(t *testing.T) {
+	w := New(Config{Tuning: DefaultTuning()}, gameSizes)
+	events := recordEvents(w)
+	hitbox := w.PilotRect(PlayerOne)
+	w.PowerUps = []PowerUp{{Point: Point{X: float64(hitbox.Min.X), Y: float64(hitbox.Min.Y)}}}
+	w.handlePowerUpCollisions()
+	if w.PowerUpTicks != powerUpDuration || len(w.PowerUps) != 0 {
+		t.Fatalf("power-up ticks = %d items = %d, want the item collected", w.PowerUpTicks, len(w.PowerUps))
+	}
+	if want := []Event{PowerUpCollected{Player: PlayerOne}}; !reflect.DeepEqual(*events, want) {
+		t.Fatalf("events = %v, want %v", *events, want)
+	}
+}
+
+func TestDiagonalProjectilesAreRemovedOutsideHorizontalBounds(t *testing.T) {
+	imageWidth := 8
+	imageHeight := 8
+	if !projectileOffscreen(Projectile{Point: Point{X: -9, Y: 100}}, imageWidth, imageHeight) {
+		t.Fatal("projectile beyond the left edge should be offscreen")
+	}
+	if !projectileOffscreen(Projectile{Point: Point{X: ScreenWidth + 1, Y: 100}}, imageWidth, imageHeight) {
+		t.Fatal("projectile beyond the right edge should be offscreen")
+	}
+	if projectileOffscreen(Projectile{Point: Point{X: 0, Y: 100}}, imageWidth, imageHeight) {
+		t.Fatal("visible projectile should not be offscreen")
+	}
+}
+
+func TestPowerUpDropUsesConfiguredRate(t *testing.T) {
+	w := testWorld()
+	for range powerUpDropRate * 20 {
+		w.maybeDropPowerUp(Point{X: 12, Y: 34})
+	}
+	if len(w.PowerUps) == 0 || len(w.PowerUps) == powerUpDropRate*20 {
+		t.Fatalf("power-up drops = %d, want some but not all attempts", len(w.PowerUps))
+	}
+	for _, item := range w.PowerUps {
+		if item.X != 12 || item.Y != 34 {
+			t.Fatalf("power-up position = %+v, want (12,34)", item)
+		}
+	}
+}
+
+func TestShotsEmitProjectileCount(t *testing.T) {
+	w := testWorld()
+	events := recordEvents(w)
+	w.fireProjectiles(PlayerOne, 0, 0)
+	w.PowerUpTicks = 1
+	w.fireProjectiles(PlayerTwo, 0, 0)
+	want := []Event{ShotFired{Shooter: PlayerOne, Count: 1}, ShotFired{Shooter: PlayerTwo, Count: PowerUpShotCount}}
+	if !reflect.DeepEqual(*events, want) {
+		t.Fatalf("events = %v, want %v", *events, want)
+	}
+}
+
+func TestSpecialAttackOnBossEmitsEvents(t *testing.T) {
+	w := testWorld()
+	w.Boss = &Boss{HP: 100}
+	w.Pilots[PlayerOne].MissCount = SpecialCost
+	events := recordEvents(w)
+	w.useSpecialAttack(PlayerOne)
+	want := []Event{SpecialUsed{Shooter: PlayerOne}, BossDamaged{Shooter: PlayerOne, Damage: bossSpecialHit, Special: true}}
+	if !reflect.DeepEqual(*events, want) {
+		t.Fatalf("events = %v, want %v", *events, want)
+	}
+}
+
+func TestClearingAWaveEmitsClearedThenStarted(t *testing.T) {
+	w := testWorld()
+	events := recordEvents(w)
+	w.clearWave()
+	want := []Event{WaveCleared{Wave: 1}, WaveStarted{Wave: 2}}
+	if !reflect.DeepEqual(*events, want) || w.Wave != 2 {
+		t.Fatalf("events = %v wave = %d, want %v", *events, w.Wave, want)
+	}
+}
+
+func TestSameSeedPlaysTheSameRun(t *testing.T) {
+	run := func() uint64 {
+		w := New(Config{Tuning: DefaultTuning(), Seed: 7}, gameSizes)
+		for tick := range 2000 {
+			w.Step([2]Controls{{Fire: true, Left: tick%200 < 100, Right: tick%200 >= 100}})
+		}
+		return w.Hash()
+	}
+	if first, second := run(), run(); first != second {
+		t.Fatalf("hashes = %016x and %016x, want the same run from the same seed", first, second)
+	}
+}
+
+func TestLoadSizesReadsImageHeaders(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewGray(image.Rect(0, 0, 12, 34))); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"ebisan.png", "ufo.png", "o.png", "bashihebi.png", "ebi.png", "boss_ebi.png"} {
+		fsys[name] = &fstest.MapFile{Data: data.Bytes()}
+	}
+	sizes, err := LoadSizes(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sizes.Boss != image.Pt(12, 34) || sizes.Player != image.Pt(12, 34) {
+		t.Fatalf("sizes = %+v, want 12x34 sprites", sizes)
+	}
+	delete(fsys, "ufo.png")
+	if _, err := LoadSizes(fsys); err == nil {
+		t.Fatal("LoadSizes succeeded with a missing sprite")
+	}
+}
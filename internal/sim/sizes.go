@@ -0,0 +1,37 @@
+package sim
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+)
+
+// LoadSizes reads the sprite sizes from the image headers in fsys, for tools
+// that run the simulation without loading the game.
+func LoadSizes(fsys fs.FS) (Sizes, error) {
+	var sizes Sizes
+	for _, sprite := range []struct {
+		path string
+		size *image.Point
+	}{
+		{"ebisan.png", &sizes.Player},
+		{"ufo.png", &sizes.UFO},
+		{"o.png", &sizes.Projectile},
+		{"bashihebi.png", &sizes.BashiHebi},
+		{"ebi.png", &sizes.Ebi},
+		{"boss_ebi.png", &sizes.Boss},
+	} {
+		file, err := fsys.Open(sprite.path)
+		if err != nil {
+			return Sizes{}, fmt.Errorf("open image %q: %w", sprite.path, err)
+		}
+		config, _, err := image.DecodeConfig(file)
+		file.Close()
+		if err != nil {
+			return Sizes{}, fmt.Errorf("decode image %q: %w", sprite.path, err)
+		}
+		*sprite.size = image.Pt(config.Width, config.Height)
+	}
+	return sizes, nil
+}
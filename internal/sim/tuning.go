@@ -0,0 +1,150 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Default tuning values.
+const (
+	playerSpeed          = 4
+	projectileSpeed      = 2
+	enemySpeed           = 2
+	enemySpeedGain       = 0.25
+	maxEnemySpeed        = 6
+	fallingSpeedBase     = 1.2
+	fallingSpeedGain     = 0.25
+	maxFallingSpeed      = 5
+	bossSpeed            = 1.5
+	powerUpSpeed         = 1.5
+	powerUpDiagonalSpeed = 1.4
+	shotInterval         = 10 // At 60 TPS, holding Space fires about six shots per second.
+	powerUpDropRate      = 5  // One in five defeated UFOs drops an item.
+	powerUpDuration      = 10 * 60
+	bossAttackTime       = 75
+	bossBaseHP           = 30
+	bossHPGrowth         = 15
+	ufoChance            = 2 // UFOs spawn on ufoChance in 120 ticks, plus one every two waves.
+	maxUFOChance         = 6
+)
+
+// Tuning holds the gameplay numbers that can be changed without a rebuild:
+// the game reloads them from tuning.json in debug mode and the balance tool
+// takes the same file.
+type Tuning struct {
+	PlayerSpeed          float64 `json:"playerSpeed"`
+	ProjectileSpeed      float64 `json:"projectileSpeed"`
+	EnemySpeed           float64 `json:"enemySpeed"`
+	EnemySpeedGain       float64 `json:"enemySpeedGain"`
+	MaxEnemySpeed        float64 `json:"maxEnemySpeed"`
+	FallingSpeedBase     float64 `json:"fallingSpeedBase"`
+	FallingSpeedGain     float64 `json:"fallingSpeedGain"`
+	MaxFallingSpeed      float64 `json:"maxFallingSpeed"`
+	BossSpeed            float64 `json:"bossSpeed"`
+	PowerUpSpeed         float64 `json:"powerUpSpeed"`
+	PowerUpDiagonalSpeed float64 `json:"powerUpDiagonalSpeed"`
+	ShotInterval         int     `json:"shotInterval"`
+	PowerUpDropRate      int     `json:"powerUpDropRate"`
+	PowerUpDuration      int     `json:"powerUpDuration"`
+	BossAttackTime       int     `json:"bossAttackTime"`
+	BossBaseHP           int     `json:"bossBaseHP"`
+	BossHPGrowth         int     `json:"bossHPGrowth"`
+	UFOChance            int     `json:"ufoChance"`
+	MaxUFOChance         int     `json:"maxUFOChance"`
+}
+
+func DefaultTuning() Tuning {
+	return Tuning{
+		PlayerSpeed:          playerSpeed,
+		ProjectileSpeed:      projectileSpeed,
+		EnemySpeed:           enemySpeed,
+		EnemySpeedGain:       enemySpeedGain,
+		MaxEnemySpeed:        maxEnemySpeed,
+		FallingSpeedBase:     fallingSpeedBase,
+		FallingSpeedGain:     fallingSpeedGain,
+		MaxFallingSpeed:      maxFallingSpeed,
+		BossSpeed:            bossSpeed,
+		PowerUpSpeed:         powerUpSpeed,
+		PowerUpDiagonalSpeed: powerUpDiagonalSpeed,
+		ShotInterval:         shotInterval,
+		PowerUpDropRate:      powerUpDropRate,
+		PowerUpDuration:      powerUpDuration,
+		BossAttackTime:       bossAttackTime,
+		BossBaseHP:           bossBaseHP,
+		BossHPGrowth:         bossHPGrowth,
+		UFOChance:            ufoChance,
+		MaxUFOChance:         maxUFOChance,
+	}
+}
+
+// ParseTuning overlays a tuning file on the defaults. Keys that are missing
+// keep their default value and unknown keys are rejected to catch typos.
+func ParseTuning(data []byte) (Tuning, error) {
+	values := DefaultTuning()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&values); err != nil {
+		return Tuning{}, err
+	}
+	if err := values.validate(); err != nil {
+		return Tuning{}, err
+	}
+	return values, nil
+}
+
+func (values Tuning) validate() error {
+	positive := []struct {
+		name  string
+		value int
+	}{
+		{"shotInterval", values.ShotInterval},
+		{"powerUpDropRate", values.PowerUpDropRate},
+		{"bossAttackTime", values.BossAttackTime},
+		{"bossBaseHP", values.BossBaseHP},
+	}
+	var errs []error
+	for _, field := range positive {
+		if field.value < 1 {
+			errs = append(errs, fmt.Errorf("%s must be at least 1, got %d", field.name, field.value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TuningChanges describes every value that differs between two tunings,
+// using the JSON key names from the tuning file.
+func TuningChanges(before, after Tuning) []string {
+	var changes []string
+	beforeValue := reflect.ValueOf(before)
+	afterValue := reflect.ValueOf(after)
+	for index := range beforeValue.NumField() {
+		oldValue := beforeValue.Field(index).Interface()
+		newValue := afterValue.Field(index).Interface()
+		if oldValue == newValue {
+			continue
+		}
+		name := beforeValue.Type().Field(index).Tag.Get("json")
+		changes = append(changes, fmt.Sprintf("%s %v -> %v", name, oldValue, newValue))
+	}
+	return changes
+}
+
+func (values Tuning) bossHealth(wave int) int {
+	return values.BossBaseHP + max(0, wave/BossWaveCycle-1)*values.BossHPGrowth
+}
+
+func (values Tuning) enemySpeed(wave int) float64 {
+	return min(values.MaxEnemySpeed, values.EnemySpeed+float64(float64(max(0, wave-1))*values.EnemySpeedGain))
+}
+
+func (values Tuning) fallingSpeed(wave int) float64 {
+	return min(values.MaxFallingSpeed, values.FallingSpeedBase+float64(float64(max(0, wave-1))*values.FallingSpeedGain))
+}
+
+// ufoChance is the UFO spawn roll out of 120 for a wave.
+func (values Tuning) ufoChance(wave int) int {
+	return min(values.MaxUFOChance, values.UFOChance+wave/2)
+}
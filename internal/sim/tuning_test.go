@@ -0,0 +1,49 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTuningKeepsDefaultsForMissingKeys(t *testing.T) {
+	values, err := ParseTuning([]byte(`{"shotInterval": 6, "bossSpeed": 2.5}`))
+	if err != nil {
+		t.Fatalf("parse tuning: %v", err)
+	}
+	want := DefaultTuning()
+	want.ShotInterval = 6
+	want.BossSpeed = 2.5
+	if values != want {
+		t.Fatalf("tuning = %+v, want %+v", values, want)
+	}
+}
+
+func TestParseTuningRejectsTyposAndInvalidValues(t *testing.T) {
+	for _, data := range []string{
+		`{"shotIntervall": 6}`,
+		`{"powerUpDropRate": 0}`,
+		`{"shotInterval": "fast"}`,
+	} {
+		if _, err := ParseTuning([]byte(data)); err == nil {
+			t.Fatalf("parse %s succeeded, want an error", data)
+		}
+	}
+}
+
+func TestTuningChangesNamesEachChangedValue(t *testing.T) {
+	after := DefaultTuning()
+	after.ShotInterval = 6
+	after.PowerUpDropRate = 3
+
+	changes := TuningChanges(DefaultTuning(), after)
+	want := []string{
+		"shotInterval 10 -> 6",
+		"powerUpDropRate 5 -> 3",
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("changes = %q, want %q", changes, want)
+	}
+	if changes := TuningChanges(after, after); len(changes) != 0 {
+		t.Fatalf("changes for identical tuning = %q, want none", changes)
+	}
+}
@@ -0,0 +1,244 @@
+// Package websocket implements the small part of RFC 6455 that the online
+// relay needs: the opening handshake on both sides and text messages, with
+// pings answered and closes reported as io.EOF.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	acceptGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxMessageSize = 1 << 16
+	dialTimeout    = 10 * time.Second
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+var errMessageTooLarge = errors.New("websocket: message too large")
+
+// Conn is an open WebSocket connection. One goroutine may read while others
+// write.
+type Conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	client  bool // Clients mask the frames they send.
+	writeMu sync.Mutex
+}
+
+// Accept upgrades an HTTP request to a WebSocket connection.
+func Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket upgrade unsupported", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response cannot be hijacked")
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+	fmt.Fprintf(buffered, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err := buffered.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	return &Conn{conn: conn, reader: buffered.Reader}, nil
+}
+
+// Dial opens a connection to a ws:// or wss:// URL.
+func Dial(rawURL string) (*Conn, error) {
+	address, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+	host := address.Host
+	if address.Port() == "" {
+		port := "80"
+		if address.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(address.Hostname(), port)
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	switch address.Scheme {
+	case "ws":
+		conn, err = dialer.Dial("tcp", host)
+	case "wss":
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: address.Hostname()})
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", address.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("websocket: dial %s: %w", host, err)
+	}
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	request := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n",
+		address.RequestURI(), address.Host, key)
+	if _, err := io.WriteString(conn, request); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, &http.Request{Method: http.MethodGet})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake refused: %s", response.Status)
+	}
+	return &Conn{conn: conn, reader: reader, client: true}, nil
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// ReadMessage returns the next text or binary message. It answers pings on
+// the way and returns io.EOF once the peer closes the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		var header [2]byte
+		if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+			return nil, err
+		}
+		final := header[0]&0x80 != 0
+		opcode := header[0] & 0x0f
+		masked := header[1]&0x80 != 0
+		length := uint64(header[1] & 0x7f)
+		switch length {
+		case 126:
+			var extended [2]byte
+			if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+				return nil, err
+			}
+			length = uint64(binary.BigEndian.Uint16(extended[:]))
+		case 127:
+			var extended [8]byte
+			if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+				return nil, err
+			}
+			length = binary.BigEndian.Uint64(extended[:])
+		}
+		if length > maxMessageSize || uint64(len(message))+length > maxMessageSize {
+			return nil, errMessageTooLarge
+		}
+		var mask [4]byte
+		if masked {
+			if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+				return nil, err
+			}
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.reader, payload); err != nil {
+			return nil, err
+		}
+		if masked {
+			for index := range payload {
+				payload[index] ^= mask[index%4]
+			}
+		}
+
+		switch opcode {
+		case opClose:
+			c.writeFrame(opClose, nil)
+			return nil, io.EOF
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opText, opBinary, opContinuation:
+			message = append(message, payload...)
+			if final {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %#x", opcode)
+		}
+	}
+}
+
+// WriteMessage sends data as a single text frame.
+func (c *Conn) WriteMessage(data []byte) error {
+	if len(data) > maxMessageSize {
+		return errMessageTooLarge
+	}
+	return c.writeFrame(opText, data)
+}
+
+// SetReadDeadline makes reads fail once t has passed.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close tells the peer the connection is closing and closes it.
+func (c *Conn) Close() error {
+	c.writeFrame(opClose, nil)
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	if c.client {
+		var mask [4]byte
+		rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		for index, value := range payload {
+			frame = append(frame, value^mask[index%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
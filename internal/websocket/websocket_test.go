@@ -0,0 +1,78 @@
+//go:build !js
+
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEchoRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(message); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	conn, err := Dial("ws" + strings.TrimPrefix(server.URL, "http") + "/echo?room=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, message := range [][]byte{[]byte(`{"type":"input"}`), bytes.Repeat([]byte("x"), 300), bytes.Repeat([]byte("y"), 40000)} {
+		if err := conn.WriteMessage(message); err != nil {
+			t.Fatal(err)
+		}
+		echoed, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(echoed, message) {
+			t.Fatalf("echoed %d bytes, want the %d bytes sent", len(echoed), len(message))
+		}
+	}
+}
+
+func TestReadMessageReportsClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, err := Accept(w, r); err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	conn, err := Dial("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ReadMessage(); err != io.EOF {
+		t.Fatalf("err = %v, want io.EOF after the server closed", err)
+	}
+}
+
+func TestAcceptRejectsPlainRequests(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	if _, err := Accept(recorder, httptest.NewRequest(http.MethodGet, "/relay", nil)); err == nil {
+		t.Fatal("Accept upgraded a request without WebSocket headers")
+	}
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
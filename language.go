@@ -0,0 +1,128 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// language is a language code matching a file in lang/. The empty language
+// follows the OS locale or the browser's navigator.language.
+type language string
+
+const (
+	languageAuto     language = ""
+	languageJapanese language = "ja"
+	languageEnglish  language = "en"
+)
+
+// languages lists the string tables in the order the settings menu cycles
+// through them.
+var languages = []language{languageJapanese, languageEnglish}
+
+// languageNames are shown in their own language so players can find theirs.
+var languageNames = map[language]string{
+	languageJapanese: "日本語",
+	languageEnglish:  "English",
+}
+
+//go:embed lang/*.json
+var languageFiles embed.FS
+
+// messageCatalog maps message keys to fmt templates.
+type messageCatalog map[string]string
+
+func loadMessageCatalogs() (map[language]messageCatalog, error) {
+	catalogs := make(map[language]messageCatalog, len(languages))
+	for _, lang := range languages {
+		path := "lang/" + string(lang) + ".json"
+		data, err := languageFiles.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var catalog messageCatalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		catalogs[lang] = catalog
+	}
+	return catalogs, nil
+}
+
+// matchLanguage picks the string table for a locale such as "en-US",
+// "ja_JP.UTF-8" or "fr". Unknown locales get English, but an unset or POSIX
+// locale keeps the game's original Japanese.
+func matchLanguage(locale string) language {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" || locale == "c" || strings.HasPrefix(locale, "c.") || locale == "posix" {
+		return languageJapanese
+	}
+	for _, lang := range languages {
+		code := string(lang)
+		if locale == code || strings.HasPrefix(locale, code+"-") || strings.HasPrefix(locale, code+"_") || strings.HasPrefix(locale, code+".") {
+			return lang
+		}
+	}
+	return languageEnglish
+}
+
+func knownLanguage(lang language) bool {
+	_, ok := languageNames[lang]
+	return ok
+}
+
+// language is the language currently shown, after resolving the automatic
+// setting.
+func (g *Game) language() language {
+	if knownLanguage(g.settings.Language) {
+		return g.settings.Language
+	}
+	if knownLanguage(g.systemLanguage) {
+		return g.systemLanguage
+	}
+	return languageJapanese
+}
+
+// message formats a player-facing string in the current language. Keys
+// missing from a translation fall back to Japanese, then to the key itself.
+func (g *Game) message(key string, args ...any) string {
+	template, ok := g.catalogs[g.language()][key]
+	if !ok {
+		template, ok = g.catalogs[languageJapanese][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+func (g *Game) cycleLanguage(delta int) {
+	options := append([]language{languageAuto}, languages...)
+	current := 0
+	for index, lang := range options {
+		if lang == g.settings.Language {
+			current = index
+		}
+	}
+	g.settings.Language = options[(current+delta+len(options))%len(options)]
+	g.applyLanguage()
+	g.saveSettings()
+}
+
+func (g *Game) languageLabel() string {
+	if g.settings.Language == languageAuto {
+		return g.message("settings.languageAuto", languageNames[g.language()])
+	}
+	return languageNames[g.settings.Language]
+}
+
+// applyLanguage updates text that lives outside Draw.
+func (g *Game) applyLanguage() {
+	ebiten.SetWindowTitle(g.message("window.title"))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestLanguageFilesShareKeysAndFormatVerbs(t *testing.T) {
+	catalogs, err := loadMessageCatalogs()
+	if err != nil {
+		t.Fatalf("load catalogs: %v", err)
+	}
+	japanese := catalogs[languageJapanese]
+	for _, lang := range languages {
+		catalog := catalogs[lang]
+		if len(catalog) != len(japanese) {
+			t.Fatalf("%s has %d messages, want %d like ja", lang, len(catalog), len(japanese))
+		}
+		for key, template := range japanese {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Fatalf("%s is missing %q", lang, key)
+			}
+			if want, got := formatVerb.FindAllString(template, -1), formatVerb.FindAllString(translated, -1); !slices.Equal(got, want) {
+				t.Fatalf("%s %q uses verbs %v, want %v", lang, key, got, want)
+			}
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	tests := map[string]language{
+		"":            languageJapanese,
+		"C.UTF-8":     languageJapanese,
+		"ja_JP.UTF-8": languageJapanese,
+		"ja":          languageJapanese,
+		"en-US":       languageEnglish,
+		"en_GB.UTF-8": languageEnglish,
+		"fr-FR":       languageEnglish,
+		"jam":         languageEnglish,
+	}
+	for locale, want := range tests {
+		if got := matchLanguage(locale); got != want {
+			t.Errorf("matchLanguage(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestMessageFollowsSettingThenSystemLanguage(t *testing.T) {
+	g := &Game{
+		catalogs: map[language]messageCatalog{
+			languageJapanese: {"banner.wave": "WAVE %d: UFOを%d体倒せ！", "only.ja": "日本語だけ"},
+			languageEnglish:  {"banner.wave": "WAVE %d: Shoot down %d UFOs!"},
+		},
+		systemLanguage: languageEnglish,
+	}
+	if got := g.message("banner.wave", 2, 6); got != "WAVE 2: Shoot down 6 UFOs!" {
+		t.Fatalf("automatic language message = %q", got)
+	}
+	if got := g.message("only.ja"); got != "日本語だけ" {
+		t.Fatalf("missing translation = %q, want the Japanese text", got)
+	}
+	if got := g.message("missing.key"); got != "missing.key" {
+		t.Fatalf("unknown key = %q, want the key", got)
+	}
+
+	g.settings.Language = languageJapanese
+	if got := g.message("banner.wave", 2, 6); got != "WAVE 2: UFOを6体倒せ！" {
+		t.Fatalf("chosen language message = %q", got)
+	}
+}
+
+func TestDecodeSettingsResetsUnknownLanguage(t *testing.T) {
+	decoded, err := decodeSettings([]byte(`{"language":"xx"}`))
+	if err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	if decoded.Language != languageAuto {
+		t.Fatalf("language = %q, want automatic", decoded.Language)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !js && !windows
+
+package main
+
+import "os"
+
+// systemLocale reads the POSIX locale variables in order of precedence.
+func systemLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
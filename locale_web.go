@@ -0,0 +1,16 @@
+//go:build js
+
+package main
+
+import "syscall/js"
+
+func systemLocale() string {
+	navigator := js.Global().Get("navigator")
+	if navigator.IsUndefined() {
+		return ""
+	}
+	if locale := navigator.Get("language"); locale.Type() == js.TypeString {
+		return locale.String()
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const localeNameMaxLength = 85
+
+var procGetUserDefaultLocaleName = syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// systemLocale honors LANG for players who set it in their shell, then asks
+// Windows for the user's display locale such as "ja-JP".
+func systemLocale() string {
+	if value := os.Getenv("LANG"); value != "" {
+		return value
+	}
+	var buffer [localeNameMaxLength]uint16
+	length, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buffer[0])), localeNameMaxLength)
+	if length == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buffer[:])
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+const (
+	menuTop       = 140
+	menuRowHeight = 36
+	menuLeft      = 100
+	menuRight     = screenWidth - menuLeft
+	menuRows      = 8 // Rows that fit above the hint; longer menus scroll.
+)
+
+// menuItem is one adjustable row. label is a message key, and adjust receives
+// -1 or +1 from the arrow keys; a click or tap on the row counts as +1.
+type menuItem struct {
+	label  string
+	value  func() string
+	adjust func(delta int)
+}
+
+// toggleItem is a row that turns a setting on or off and saves it.
+func (g *Game) toggleItem(label string, enabled *bool) menuItem {
+	return menuItem{
+		label: label,
+		value: func() string { return g.onOff(*enabled) },
+		adjust: func(int) {
+			*enabled = !*enabled
+			g.saveSettings()
+		},
+	}
+}
+
+// menu is a list of settings rows under a title message key. A submenu
+// returns to its parent when closed.
+type menu struct {
+	title    string
+	items    []menuItem
+	selected int
+	top      int // First visible row.
+	parent   *menu
+}
+
+// update handles keyboard input and the clicks or taps at pointers, and
+// reports whether the menu is still open. Alt+Enter is left for the
+// fullscreen shortcut.
+func (m *menu) update(pointers []image.Point) bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || (inpututil.IsKeyJustPressed(ebiten.KeyEnter) && !altPressed()) {
+		return false
+	}
+	if len(m.items) == 0 {
+		return true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		m.selected = (m.selected + len(m.items) - 1) % len(m.items)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		m.selected = (m.selected + 1) % len(m.items)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		m.items[m.selected].adjust(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		m.items[m.selected].adjust(1)
+	}
+	for _, position := range pointers {
+		if row, ok := m.rowAt(position); ok {
+			m.selected = row
+			m.items[row].adjust(1)
+		}
+	}
+	m.scroll()
+	return true
+}
+
+// scroll moves the visible rows just enough to show the selected one.
+func (m *menu) scroll() {
+	m.top = min(m.top, m.selected)
+	m.top = max(m.top, m.selected-menuRows+1)
+}
+
+// visibleRows returns the range of rows on screen.
+func (m *menu) visibleRows() (int, int) {
+	return m.top, min(len(m.items), m.top+menuRows)
+}
+
+func (m *menu) rowY(row int) int {
+	return menuTop + (row-m.top)*menuRowHeight
+}
+
+func (m *menu) rowRect(row int) image.Rectangle {
+	y := m.rowY(row)
+	return image.Rect(menuLeft-10, y-24, menuRight+10, y+8)
+}
+
+func (m *menu) rowAt(position image.Point) (int, bool) {
+	first, last := m.visibleRows()
+	for row := first; row < last; row++ {
+		if position.In(m.rowRect(row)) {
+			return row, true
+		}
+	}
+	return 0, false
+}
+
+func (g *Game) drawMenu(screen *ebiten.Image, m *menu) {
+	ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{A: 150})
+	g.drawCenteredText(screen, g.message(m.title), 90, color.White)
+	first, last := m.visibleRows()
+	for row := first; row < last; row++ {
+		item := m.items[row]
+		clr := color.Color(color.White)
+		if row == m.selected {
+			rect := m.rowRect(row)
+			ebitenutil.DrawRect(screen, float64(rect.Min.X), float64(rect.Min.Y), float64(rect.Dx()), float64(rect.Dy()), color.RGBA{R: 55, G: 70, B: 140, A: 200})
+			clr = color.RGBA{R: 255, G: 220, B: 70, A: 255}
+		}
+		y := m.rowY(row)
+		text.Draw(screen, g.message(item.label), g.font, menuLeft, y, clr)
+		value := item.value()
+		_, advance := font.BoundString(g.font, value)
+		text.Draw(screen, value, g.font, menuRight-advance.Ceil(), y, clr)
+	}
+	// Arrows show that more rows are hidden above or below.
+	scrollColor := color.RGBA{R: 130, G: 220, B: 255, A: 255}
+	if first > 0 {
+		g.drawCenteredText(screen, "▲", menuTop-menuRowHeight+4, scrollColor)
+	}
+	if last < len(m.items) {
+		g.drawCenteredText(screen, "▼", m.rowY(last)-4, scrollColor)
+	}
+	g.drawCenteredText(screen, g.message("menu.hint"), screenHeight-30, color.RGBA{R: 130, G: 220, B: 255, A: 255})
+}
+
+// submenuItem is a row that opens another page of the menu.
+func (g *Game) submenuItem(label string, items func() []menuItem) menuItem {
+	return menuItem{
+		label: label,
+		value: func() string { return ">" },
+		adjust: func(delta int) {
+			if delta > 0 {
+				g.menu = &menu{title: label, items: items(), parent: g.menu}
+			}
+		},
+	}
+}
+
+// closeMenu goes back one page, or to the title from the top page.
+func (g *Game) closeMenu() {
+	if g.menu != nil && g.menu.parent != nil {
+		g.menu = g.menu.parent
+		return
+	}
+	g.menu = nil
+	g.state = stateTitle
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestMenuScrollsToSelectedRow(t *testing.T) {
+	m := &menu{items: make([]menuItem, menuRows+3)}
+	m.selected = menuRows + 1
+	m.scroll()
+	if first, last := m.visibleRows(); first != 2 || last != menuRows+2 {
+		t.Fatalf("visible rows = %d-%d, want the selected row at the bottom", first, last)
+	}
+	if m.rowY(m.selected) != menuTop+(menuRows-1)*menuRowHeight {
+		t.Fatalf("selected row drawn at y = %d", m.rowY(m.selected))
+	}
+	m.selected = 0
+	m.scroll()
+	if m.top != 0 {
+		t.Fatalf("top = %d after selecting the first row, want 0", m.top)
+	}
+}
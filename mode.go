@@ -0,0 +1,133 @@
+package main
+
+import (
+	"image"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+// gameMode picks the rules for a run. The empty mode is the original wave
+// progression.
+type gameMode string
+
+const (
+	modeWaves       gameMode = ""
+	modeScoreAttack gameMode = "scoreAttack"
+	modeSurvival    gameMode = "survival"
+	modeBossRush    gameMode = "bossRush"
+	modeDaily       gameMode = "daily"
+	modePractice    gameMode = "practice"
+)
+
+// gameModes lists the modes in the order the title screen cycles through them.
+var gameModes = []gameMode{modeWaves, modeScoreAttack, modeSurvival, modeBossRush, modeDaily, modePractice}
+
+const titleModeY = screenHeight/2 - 154
+
+func knownGameMode(mode gameMode) bool {
+	for _, known := range gameModes {
+		if mode == known {
+			return true
+		}
+	}
+	return false
+}
+
+func gameModeKey(mode gameMode) string {
+	if mode == modeWaves {
+		return "waves"
+	}
+	return string(mode)
+}
+
+func (g *Game) gameModeName(mode gameMode) string {
+	return g.message("mode." + gameModeKey(mode))
+}
+
+func (g *Game) cycleGameMode(delta int) {
+	current := 0
+	for index, mode := range gameModes {
+		if mode == g.settings.Mode {
+			current = index
+		}
+	}
+	g.settings.Mode = gameModes[min(len(gameModes)-1, max(0, current+delta))]
+	g.saveSettings()
+	g.loadHighScore()
+}
+
+// rules are the simulation rules the mode plays by. The daily challenge and
+// practice play the normal waves with their own setup.
+func (mode gameMode) rules() sim.Mode {
+	switch mode {
+	case modeScoreAttack:
+		return sim.ModeScoreAttack
+	case modeSurvival:
+		return sim.ModeSurvival
+	case modeBossRush:
+		return sim.ModeBossRush
+	}
+	return sim.ModeWaves
+}
+
+// startMode sets up the world for the mode chosen on the title screen.
+func (g *Game) startMode() {
+	g.mode = g.settings.Mode
+	seed := time.Now().UnixNano()
+	if g.mode == modeDaily {
+		g.startDaily(time.Now())
+		seed = dailySeed(g.daily.date)
+	}
+	g.world = g.newWorld(seed, g.settings.Coop.Enabled)
+	if g.mode == modePractice {
+		g.startPractice()
+	}
+}
+
+// ranked reports whether the run can set a high score.
+func (g *Game) ranked() bool {
+	return !g.assisted && !g.dailyUnranked() && g.mode != modePractice && g.net == nil
+}
+
+// clock formats ticks as minutes and seconds, rounding up so a countdown
+// shows 0:00 only when it has run out.
+func clock(ticks int) (minutes, seconds int) {
+	total := (max(0, ticks) + 59) / 60
+	return total / 60, total % 60
+}
+
+func (g *Game) waveStatus() string {
+	world := g.world
+	switch {
+	case world.Mode == sim.ModeSurvival:
+		minutes, seconds := clock(world.ModeTicks)
+		return g.message("hud.survival", minutes, seconds, world.Level())
+	case sim.IsBossWave(world.Wave):
+		return g.message("hud.bossWave", world.Wave)
+	}
+	return g.message("hud.wave", world.Wave, world.UFOKills, sim.UFOTarget(world.Wave))
+}
+
+func (g *Game) gameOverHeading() string {
+	if g.world.Mode == sim.ModeScoreAttack && g.world.ModeTicks <= 0 {
+		return g.message("gameOver.timeUp")
+	}
+	return g.message("gameOver.heading")
+}
+
+func modeRowRect() image.Rectangle {
+	return image.Rect(screenWidth/2-140, titleModeY-26, screenWidth/2+140, titleModeY+8)
+}
+
+// modeTapped reports a click or tap on the title's mode row, which cycles the
+// mode instead of starting a run.
+func (g *Game) modeTapped() bool {
+	row := modeRowRect()
+	for _, position := range g.justPressedPointerPositions() {
+		if position.In(row) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestScoreAttackShowsTimeUp(t *testing.T) {
+	g := &Game{state: statePlaying}
+	g.settings.Mode = modeScoreAttack
+	g.startMode()
+	if g.world.Mode != sim.ModeScoreAttack || g.world.ModeTicks != sim.ScoreAttackTime {
+		t.Fatalf("mode = %q modeTicks = %d, want a %d tick score attack", g.world.Mode, g.world.ModeTicks, sim.ScoreAttackTime)
+	}
+	if g.gameOverHeading() != "gameOver.heading" {
+		t.Fatal("time-up heading shown before the timer ran out")
+	}
+	g.world.ModeTicks = 0
+	if g.gameOverHeading() != "gameOver.timeUp" {
+		t.Fatalf("heading = %q, want a time-up game over", g.gameOverHeading())
+	}
+}
+
+func TestModesPickTheirRules(t *testing.T) {
+	want := map[gameMode]sim.Mode{
+		modeWaves:       sim.ModeWaves,
+		modeScoreAttack: sim.ModeScoreAttack,
+		modeSurvival:    sim.ModeSurvival,
+		modeBossRush:    sim.ModeBossRush,
+		modeDaily:       sim.ModeWaves,
+		modePractice:    sim.ModeWaves,
+	}
+	for mode, rules := range want {
+		if got := mode.rules(); got != rules {
+			t.Errorf("%q plays by %q, want %q", mode, got, rules)
+		}
+	}
+}
+
+func TestHighScoreVariantsSeparateModesDifficultiesAndCoop(t *testing.T) {
+	seen := map[string]bool{}
+	for _, mode := range gameModes {
+		for _, level := range sim.Difficulties {
+			for _, coop := range []bool{false, true} {
+				variant := highScoreVariant(mode, level, coop)
+				if seen[variant] {
+					t.Fatalf("%q, %q and coop=%v share the high score variant %q", mode, level, coop, variant)
+				}
+				seen[variant] = true
+			}
+		}
+	}
+	if highScoreVariant(modeWaves, sim.DifficultyNormal, false) != "" {
+		t.Fatal("waves on normal should keep the original high score")
+	}
+}
+
+func TestClockRoundsUp(t *testing.T) {
+	tests := []struct{ ticks, minutes, seconds int }{
+		{sim.ScoreAttackTime, 2, 0},
+		{61 * 60, 1, 1},
+		{1, 0, 1},
+		{0, 0, 0},
+	}
+	for _, test := range tests {
+		if minutes, seconds := clock(test.ticks); minutes != test.minutes || seconds != test.seconds {
+			t.Errorf("clock(%d) = %d:%02d, want %d:%02d", test.ticks, minutes, seconds, test.minutes, test.seconds)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+)
+
+const (
+	musicFadeTicks = 90 // Crossfades take one and a half seconds at 60 TPS.
+	musicFadeStep  = 1.0 / musicFadeTicks
+)
+
+// musicStem is an optional layer that fades in once the player reaches
+// fromWave. Stems must have the same length and loop points as BGM.ogg so
+// they stay on the beat.
+type musicStem struct {
+	path     string
+	fromWave int
+}
+
+var musicStems = []musicStem{
+	{"BGM_stem1.ogg", 3},
+	{"BGM_stem2.ogg", 7},
+}
+
+const (
+	baseMusicPath    = "BGM.ogg"
+	bossMusicPath    = "BGM_boss.ogg"
+	victoryStingPath = "victory.ogg"
+)
+
+type musicLayer struct {
+	player   *audio.Player
+	fromWave int
+	gain     float64
+}
+
+// musicSystem plays BGM.ogg with intensity stems layered on top, crossfades
+// to a boss track during boss waves and plays a sting when a boss is beaten.
+// Every file is optional: without BGM.ogg the synthesized loop plays instead,
+// and without victory.ogg the synthesized jingle does.
+type musicSystem struct {
+	layers  []*musicLayer // BGM.ogg first, then the stems.
+	boss    *musicLayer
+	sting   *audio.Player
+	playing bool
+}
+
+func loadMusic(context *audio.Context, synthesized bool) (*musicSystem, error) {
+	if synthesized {
+		return synthMusic(context)
+	}
+	base, err := loadMusicTrack(context, baseMusicPath, true)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("%v; using synthesized music", err)
+		return synthMusic(context)
+	}
+	if err != nil {
+		return nil, err
+	}
+	music := &musicSystem{layers: []*musicLayer{{player: base, fromWave: 1}}}
+	for _, stem := range musicStems {
+		player, err := loadOptionalMusicTrack(context, stem.path, true)
+		if err != nil {
+			return nil, err
+		}
+		if player != nil {
+			music.layers = append(music.layers, &musicLayer{player: player, fromWave: stem.fromWave})
+		}
+	}
+	bossPlayer, err := loadOptionalMusicTrack(context, bossMusicPath, true)
+	if err != nil {
+		return nil, err
+	}
+	if bossPlayer != nil {
+		music.boss = &musicLayer{player: bossPlayer}
+	}
+	music.sting, err = loadOptionalMusicTrack(context, victoryStingPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if music.sting == nil {
+		music.sting = context.NewPlayerFromBytes(synthVictoryJingle())
+	}
+	return music, nil
+}
+
+// synthMusic plays the synthesized loop with no stems or boss track, since
+// recorded layers would not line up with it.
+func synthMusic(context *audio.Context) (*musicSystem, error) {
+	loop := synthBGM()
+	base, err := context.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(loop), int64(len(loop))))
+	if err != nil {
+		return nil, fmt.Errorf("create player for synthesized music: %w", err)
+	}
+	return &musicSystem{
+		layers: []*musicLayer{{player: base, fromWave: 1}},
+		sting:  context.NewPlayerFromBytes(synthVictoryJingle()),
+	}, nil
+}
+
+func loadOptionalMusicTrack(context *audio.Context, path string, loop bool) (*audio.Player, error) {
+	player, err := loadMusicTrack(context, path, loop)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return player, err
+}
+
+// loadMusicTrack decodes an OGG file. Looping tracks honor LOOPSTART together
+// with LOOPLENGTH or LOOPEND comments so an intro plays only once; without
+// them the whole file loops.
+func loadMusicTrack(context *audio.Context, path string, loop bool) (*audio.Player, error) {
+	data, err := readAsset(path)
+	if err != nil {
+		return nil, fmt.Errorf("open music %q: %w", path, err)
+	}
+
+	stream, err := vorbis.DecodeWithSampleRate(audioSampleRate, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode music %q: %w", path, err)
+	}
+	var source io.Reader = stream
+	if loop {
+		source = audio.NewInfiniteLoop(stream, stream.Length())
+		if points, ok := vorbisLoopPoints(data); ok {
+			intro, length := points.byteRange(audioSampleRate)
+			if intro+length <= stream.Length() {
+				source = audio.NewInfiniteLoopWithIntro(stream, intro, length)
+			} else {
+				log.Printf("music %q: loop points run past the end of the file; looping the whole track", path)
+			}
+		}
+	}
+	player, err := context.NewPlayer(source)
+	if err != nil {
+		return nil, fmt.Errorf("create player for %q: %w", path, err)
+	}
+	return player, nil
+}
+
+func (music *musicSystem) start() {
+	if music == nil {
+		return
+	}
+	music.playing = true
+	for _, layer := range music.layers {
+		layer.gain = 0
+		rewind(layer.player)
+	}
+	if music.boss != nil {
+		music.boss.gain = 0
+		music.boss.player.Pause()
+	}
+}
+
+func (music *musicSystem) stop() {
+	if music == nil {
+		return
+	}
+	music.playing = false
+	for _, layer := range music.allLayers() {
+		layer.player.Pause()
+	}
+	if music.sting != nil {
+		music.sting.Pause()
+	}
+}
+
+func (music *musicSystem) allLayers() []*musicLayer {
+	if music.boss == nil {
+		return music.layers
+	}
+	return append(music.layers[:len(music.layers):len(music.layers)], music.boss)
+}
+
+// stepGains moves every layer one tick closer to the mix for the current
+// wave. The base layer is always wanted outside boss waves so the music never
+// fades out between waves.
+func (music *musicSystem) stepGains(wave int, bossWave bool) {
+	bossTrack := bossWave && music.boss != nil
+	for _, layer := range music.layers {
+		target := 0.0
+		if !bossTrack && wave >= layer.fromWave {
+			target = 1
+		}
+		layer.gain = approach(layer.gain, target, musicFadeStep)
+	}
+	if music.boss != nil {
+		target := 0.0
+		if bossTrack {
+			target = 1
+		}
+		music.boss.gain = approach(music.boss.gain, target, musicFadeStep)
+	}
+}
+
+func approach(value, target, step float64) float64 {
+	if value < target {
+		return min(target, value+step)
+	}
+	return max(target, value-step)
+}
+
+// update fades the layers and keeps silent groups paused. The base layer and
+// stems pause and resume together so they stay in sync.
+func (music *musicSystem) update(wave int, bossWave bool, volume float64) {
+	if music == nil || !music.playing {
+		return
+	}
+	music.stepGains(wave, bossWave)
+
+	baseAudible := false
+	for _, layer := range music.layers {
+		baseAudible = baseAudible || layer.gain > 0
+	}
+	for _, layer := range music.layers {
+		layer.player.SetVolume(volume * layer.gain)
+		if baseAudible {
+			layer.player.Play()
+		} else {
+			layer.player.Pause()
+		}
+	}
+	if music.boss == nil {
+		return
+	}
+	music.boss.player.SetVolume(volume * music.boss.gain)
+	switch {
+	case music.boss.gain <= 0:
+		music.boss.player.Pause()
+	case !music.boss.player.IsPlaying():
+		rewind(music.boss.player)
+	}
+}
+
+func (music *musicSystem) playSting(volume float64) {
+	if music == nil || music.sting == nil {
+		return
+	}
+	music.sting.SetVolume(volume)
+	rewind(music.sting)
+}
+
+func (music *musicSystem) stingPlaying() bool {
+	return music != nil && music.sting != nil && music.sting.IsPlaying()
+}
+
+func rewind(player *audio.Player) {
+	if err := player.Rewind(); err != nil {
+		log.Printf("rewind audio: %v", err)
+		return
+	}
+	player.Play()
+}
+
+// musicLoopPoints are sample positions at the file's own sample rate, as
+// written by common loop-tagging tools.
+type musicLoopPoints struct {
+	start      int64
+	length     int64
+	sampleRate int64
+}
+
+// byteRange converts the loop to byte offsets in the decoded 16-bit stereo
+// stream at outputRate.
+func (points musicLoopPoints) byteRange(outputRate int64) (intro, length int64) {
+	intro = points.start * outputRate / points.sampleRate * bytesPerFrame
+	length = points.length * outputRate / points.sampleRate * bytesPerFrame
+	return intro, length
+}
+
+// vorbisLoopPoints reads LOOPSTART and LOOPLENGTH (or LOOPEND) from the
+// comment header of an OGG Vorbis file.
+func vorbisLoopPoints(data []byte) (musicLoopPoints, bool) {
+	packets := oggPackets(data, 2)
+	if len(packets) < 2 {
+		return musicLoopPoints{}, false
+	}
+	identification, comments := packets[0], packets[1]
+	if len(identification) < 16 || string(identification[:7]) != "\x01vorbis" || len(comments) < 7 || string(comments[:7]) != "\x03vorbis" {
+		return musicLoopPoints{}, false
+	}
+	sampleRate := int64(binary.LittleEndian.Uint32(identification[12:16]))
+
+	values := map[string]int64{}
+	for _, comment := range vorbisComments(comments[7:]) {
+		key, value, ok := strings.Cut(comment, "=")
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err == nil {
+			values[strings.ToUpper(key)] = number
+		}
+	}
+
+	start, hasStart := values["LOOPSTART"]
+	length, hasLength := values["LOOPLENGTH"]
+	if end, hasEnd := values["LOOPEND"]; !hasLength && hasEnd {
+		length, hasLength = end-start, true
+	}
+	if !hasStart || !hasLength || start < 0 || length <= 0 || sampleRate <= 0 {
+		return musicLoopPoints{}, false
+	}
+	return musicLoopPoints{start: start, length: length, sampleRate: sampleRate}, true
+}
+
+// oggPackets reassembles up to limit packets from the first logical stream
+// of an OGG file.
+func oggPackets(data []byte, limit int) [][]byte {
+	var packets [][]byte
+	var current []byte
+	for len(data) >= 27 && len(packets) < limit {
+		if string(data[:4]) != "OggS" {
+			return packets
+		}
+		segmentCount := int(data[26])
+		if len(data) < 27+segmentCount {
+			return packets
+		}
+		segments := data[27 : 27+segmentCount]
+		body := data[27+segmentCount:]
+		for _, size := range segments {
+			if len(body) < int(size) {
+				return packets
+			}
+			current = append(current, body[:size]...)
+			body = body[size:]
+			if size < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == limit {
+					return packets
+				}
+			}
+		}
+		data = body
+	}
+	return packets
+}
+
+// vorbisComments parses the vendor string and user comments that follow the
+// "\x03vorbis" packet header.
+func vorbisComments(packet []byte) []string {
+	readLength := func() (int, bool) {
+		if len(packet) < 4 {
+			return 0, false
+		}
+		length := int(binary.LittleEndian.Uint32(packet))
+		packet = packet[4:]
+		return length, length <= len(packet)
+	}
+
+	vendorLength, ok := readLength()
+	if !ok {
+		return nil
+	}
+	packet = packet[vendorLength:]
+	if len(packet) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(packet))
+	packet = packet[4:]
+
+	var comments []string
+	for range count {
+		length, ok := readLength()
+		if !ok {
+			return comments
+		}
+		comments = append(comments, string(packet[:length]))
+		packet = packet[length:]
+	}
+	return comments
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestMusicStemsFollowWaveAndBossTrackCrossfades(t *testing.T) {
+	music := &musicSystem{
+		layers: []*musicLayer{{fromWave: 1}, {fromWave: 3}},
+		boss:   &musicLayer{},
+	}
+	for range musicFadeTicks + 1 {
+		music.stepGains(1, false)
+	}
+	if music.layers[0].gain != 1 || music.layers[1].gain != 0 {
+		t.Fatalf("wave 1 gains = %v/%v, want base only", music.layers[0].gain, music.layers[1].gain)
+	}
+
+	music.stepGains(3, false)
+	if gain := music.layers[1].gain; gain <= 0 || gain >= 1 {
+		t.Fatalf("stem gain one tick after wave 3 = %v, want fading in", gain)
+	}
+
+	music.stepGains(sim.BossWaveCycle, true)
+	if music.layers[0].gain >= 1 || music.boss.gain <= 0 {
+		t.Fatalf("gains one tick into boss wave: base=%v boss=%v, want a crossfade", music.layers[0].gain, music.boss.gain)
+	}
+	for range musicFadeTicks + 1 {
+		music.stepGains(sim.BossWaveCycle, true)
+	}
+	if music.layers[0].gain != 0 || music.layers[1].gain != 0 || music.boss.gain != 1 {
+		t.Fatalf("boss wave gains: base=%v stem=%v boss=%v, want boss only", music.layers[0].gain, music.layers[1].gain, music.boss.gain)
+	}
+}
+
+func TestMusicKeepsBaseDuringBossWaveWithoutBossTrack(t *testing.T) {
+	music := &musicSystem{layers: []*musicLayer{{fromWave: 1}}}
+	for range musicFadeTicks + 1 {
+		music.stepGains(sim.BossWaveCycle, true)
+	}
+	if music.layers[0].gain != 1 {
+		t.Fatalf("base gain without a boss track = %v, want 1", music.layers[0].gain)
+	}
+}
+
+func oggPage(packets ...[]byte) []byte {
+	var segments, body []byte
+	for _, packet := range packets {
+		remaining := len(packet)
+		for remaining >= 255 {
+			segments = append(segments, 255)
+			remaining -= 255
+		}
+		segments = append(segments, byte(remaining))
+		body = append(body, packet...)
+	}
+	page := make([]byte, 27)
+	copy(page, "OggS")
+	page[26] = byte(len(segments))
+	return append(append(page, segments...), body...)
+}
+
+func vorbisHeaders(sampleRate uint32, comments ...string) []byte {
+	identification := make([]byte, 30)
+	copy(identification, "\x01vorbis")
+	identification[11] = 2
+	binary.LittleEndian.PutUint32(identification[12:], sampleRate)
+
+	comment := []byte("\x03vorbis")
+	comment = binary.LittleEndian.AppendUint32(comment, 4)
+	comment = append(comment, "test"...)
+	comment = binary.LittleEndian.AppendUint32(comment, uint32(len(comments)))
+	for _, value := range comments {
+		comment = binary.LittleEndian.AppendUint32(comment, uint32(len(value)))
+		comment = append(comment, value...)
+	}
+	return append(oggPage(identification), oggPage(comment)...)
+}
+
+func TestVorbisLoopPointsFromComments(t *testing.T) {
+	points, ok := vorbisLoopPoints(vorbisHeaders(44_100, "TITLE=BGM", "LOOPSTART=44100", "LOOPLENGTH=88200"))
+	if !ok {
+		t.Fatal("loop points were not found")
+	}
+	want := musicLoopPoints{start: 44_100, length: 88_200, sampleRate: 44_100}
+	if points != want {
+		t.Fatalf("loop points = %+v, want %+v", points, want)
+	}
+	intro, length := points.byteRange(audioSampleRate)
+	if intro != audioSampleRate*bytesPerFrame || length != 2*audioSampleRate*bytesPerFrame {
+		t.Fatalf("byte range = %d+%d, want one second of intro and two of loop at %d Hz", intro, length, audioSampleRate)
+	}
+
+	points, ok = vorbisLoopPoints(vorbisHeaders(48_000, "loopstart=100", "LoopEnd=400"))
+	if !ok || points.start != 100 || points.length != 300 {
+		t.Fatalf("LOOPEND points = %+v (ok=%t), want start 100 length 300", points, ok)
+	}
+
+	for _, data := range [][]byte{
+		vorbisHeaders(48_000, "TITLE=no loop"),
+		vorbisHeaders(48_000, "LOOPSTART=100"),
+		[]byte("not an ogg file"),
+	} {
+		if points, ok := vorbisLoopPoints(data); ok {
+			t.Fatalf("loop points = %+v, want none", points)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"net/url"
+	"sync"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	defaultRelayURL = "ws://localhost:8765/relay"
+	netInputDelay   = 3  // Ticks between reading an input and simulating it.
+	netHashInterval = 60 // Ticks between state hash exchanges.
+	maxOnlineRoom   = 9
+)
+
+// Online play runs the same deterministic simulation on both machines. Each
+// client sends its input for a tick netInputDelay ticks ahead of time, and a
+// tick only runs once both inputs for it have arrived (lockstep). The relay
+// in cmd/relay pairs the clients and hands them a shared random seed.
+
+// onlineSettings choose the room to join on the relay and the rules.
+type onlineSettings struct {
+	Versus bool `json:"versus"`
+	Room   int  `json:"room"`
+}
+
+func defaultOnlineSettings() onlineSettings {
+	return onlineSettings{Room: 1}
+}
+
+func (online onlineSettings) clamped() onlineSettings {
+	online.Room = min(maxOnlineRoom, max(1, online.Room))
+	return online
+}
+
+// roomName keeps co-op and versus players in separate rooms on the relay.
+func (online onlineSettings) roomName() string {
+	if online.Versus {
+		return fmt.Sprintf("versus-%d", online.Room)
+	}
+	return fmt.Sprintf("coop-%d", online.Room)
+}
+
+func relayAddress(base string, online onlineSettings) string {
+	address, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+	query := address.Query()
+	query.Set("room", online.roomName())
+	address.RawQuery = query.Encode()
+	return address.String()
+}
+
+// netMessage is the JSON sent through the relay. The relay itself sends
+// "hello" and "left"; the clients send "input" and "hash".
+type netMessage struct {
+	Type   string `json:"type"`
+	Player int    `json:"player,omitempty"`
+	Seed   int64  `json:"seed,omitempty"`
+	Tick   int    `json:"tick,omitempty"`
+	Input  uint8  `json:"input,omitempty"`
+	Hash   uint64 `json:"hash,omitempty"`
+}
+
+// netTransport is the connection to the relay: a WebSocket in the browser or
+// internal/websocket on desktop. Received messages go to a netInbox.
+type netTransport interface {
+	Send(data []byte) error
+	Close()
+}
+
+// netInbox collects messages from the transport's goroutine or callbacks
+// until the game loop drains them.
+type netInbox struct {
+	mu       sync.Mutex
+	messages [][]byte
+	err      error
+}
+
+func (inbox *netInbox) push(data []byte) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+	inbox.messages = append(inbox.messages, data)
+}
+
+func (inbox *netInbox) fail(err error) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+	if inbox.err == nil {
+		inbox.err = err
+	}
+}
+
+func (inbox *netInbox) drain() ([][]byte, error) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+	messages := inbox.messages
+	inbox.messages = nil
+	return messages, inbox.err
+}
+
+const (
+	inputLeft uint8 = 1 << iota
+	inputRight
+	inputFire
+	inputSpecial
+)
+
+func encodeControls(controls sim.Controls) uint8 {
+	var bits uint8
+	if controls.Left {
+		bits |= inputLeft
+	}
+	if controls.Right {
+		bits |= inputRight
+	}
+	if controls.Fire {
+		bits |= inputFire
+	}
+	if controls.Special {
+		bits |= inputSpecial
+	}
+	return bits
+}
+
+func decodeControls(bits uint8) sim.Controls {
+	return sim.Controls{
+		Left:    bits&inputLeft != 0,
+		Right:   bits&inputRight != 0,
+		Fire:    bits&inputFire != 0,
+		Special: bits&inputSpecial != 0,
+	}
+}
+
+// netSession is the lockstep state of one online game.
+type netSession struct {
+	transport netTransport
+	inbox     *netInbox
+	online    onlineSettings
+	address   string
+	local     int // The pilot this client controls, set by the relay's hello.
+	tick      int // Next tick to simulate.
+	sent      int // Next tick to send a local input for.
+	inputs    [2]map[int]uint8
+	hashes    [2]map[int]uint64
+	special   bool // A KIEE press waiting to be sent, so it is not lost while stalled.
+	current   [2]sim.Controls
+}
+
+func newNetSession(transport netTransport, inbox *netInbox, online onlineSettings, address string) *netSession {
+	return &netSession{transport: transport, inbox: inbox, online: online, address: address}
+}
+
+// start sets the player number and fills the first ticks, which run before
+// any input can arrive, with empty input.
+func (session *netSession) start(player int) {
+	session.local = player
+	session.tick = 0
+	session.sent = netInputDelay
+	for index := range session.inputs {
+		session.inputs[index] = map[int]uint8{}
+		session.hashes[index] = map[int]uint64{}
+		for tick := range netInputDelay {
+			session.inputs[index][tick] = 0
+		}
+	}
+}
+
+func (session *netSession) send(message netMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return session.transport.Send(data)
+}
+
+// queueLocal sends the local input for the tick netInputDelay ahead of the
+// one about to run. A latched KIEE press goes out with the first input sent.
+func (session *netSession) queueLocal(controls sim.Controls) error {
+	for session.sent <= session.tick+netInputDelay {
+		controls.Special = session.special
+		session.special = false
+		bits := encodeControls(controls)
+		session.inputs[session.local][session.sent] = bits
+		if err := session.send(netMessage{Type: "input", Tick: session.sent, Input: bits}); err != nil {
+			return err
+		}
+		session.sent++
+	}
+	return nil
+}
+
+// ready reports whether both inputs for the next tick are known and, if so,
+// makes them the current controls.
+func (session *netSession) ready() bool {
+	for index := range session.inputs {
+		if _, ok := session.inputs[index][session.tick]; !ok {
+			return false
+		}
+	}
+	for index := range session.inputs {
+		session.current[index] = decodeControls(session.inputs[index][session.tick])
+		delete(session.inputs[index], session.tick)
+	}
+	return true
+}
+
+// handle stores a message from the partner and returns it so the game can
+// act on the relay's hello and left notices.
+func (session *netSession) handle(data []byte) (netMessage, error) {
+	var message netMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return message, fmt.Errorf("parse message: %w", err)
+	}
+	if session.inputs[0] == nil {
+		return message, nil
+	}
+	remote := 1 - session.local
+	switch message.Type {
+	case "input":
+		session.inputs[remote][message.Tick] = message.Input
+	case "hash":
+		return message, session.recordHash(remote, message.Tick, message.Hash)
+	}
+	return message, nil
+}
+
+var errDesync = errors.New("state hashes differ")
+
+// recordHash keeps a player's state hash for a tick and compares it with the
+// other player's once both are known.
+func (session *netSession) recordHash(player, tick int, hash uint64) error {
+	other, ok := session.hashes[1-player][tick]
+	if !ok {
+		session.hashes[player][tick] = hash
+		return nil
+	}
+	delete(session.hashes[1-player], tick)
+	if other != hash {
+		return fmt.Errorf("%w at tick %d (%016x vs %016x)", errDesync, tick, hash, other)
+	}
+	return nil
+}
+
+func (g *Game) versus() bool {
+	return g.net != nil && g.net.online.Versus
+}
+
+// versusResult names the player with more points at the end of a versus game.
+func (g *Game) versusResult() string {
+	pilots := g.world.Pilots
+	switch {
+	case pilots[sim.PlayerOne].Points > pilots[sim.PlayerTwo].Points:
+		return g.message("netplay.winner", sim.PlayerOne+1)
+	case pilots[sim.PlayerTwo].Points > pilots[sim.PlayerOne].Points:
+		return g.message("netplay.winner", sim.PlayerTwo+1)
+	}
+	return g.message("netplay.draw")
+}
+
+func (g *Game) onlineMenuItems() []menuItem {
+	online := &g.settings.Online
+	return []menuItem{
+		{
+			label: "online.rules",
+			value: func() string {
+				if online.Versus {
+					return g.message("online.versus")
+				}
+				return g.message("online.coop")
+			},
+			adjust: func(int) {
+				online.Versus = !online.Versus
+				g.saveSettings()
+			},
+		},
+		{
+			label: "online.room",
+			value: func() string { return fmt.Sprintf("< %d >", online.clamped().Room) },
+			adjust: func(delta int) {
+				online.Room = min(maxOnlineRoom, max(1, online.clamped().Room+delta))
+				g.saveSettings()
+			},
+		},
+		{
+			label: "online.connect",
+			value: func() string { return ">" },
+			adjust: func(delta int) {
+				if delta > 0 {
+					g.menu = nil
+					g.connectOnline()
+				}
+			},
+		},
+	}
+}
+
+// connectOnline joins the room on the relay and waits for a partner.
+func (g *Game) connectOnline() {
+	online := g.settings.Online.clamped()
+	address := relayAddress(relayURL(), online)
+	log.Printf("netplay: joining %s", address)
+	inbox := &netInbox{}
+	g.net = newNetSession(dialRelay(address, inbox), inbox, online, address)
+	g.netNotice = ""
+	g.state = stateConnecting
+}
+
+// leaveOnline closes the connection, which tells the partner through the
+// relay.
+func (g *Game) leaveOnline() {
+	if g.net == nil {
+		return
+	}
+	g.net.transport.Close()
+	g.net = nil
+}
+
+// endOnline returns to the title with a notice of why the game ended.
+func (g *Game) endOnline(notice string, err error) {
+	if err != nil {
+		log.Printf("netplay: %s: %v", notice, err)
+	} else {
+		log.Printf("netplay: %s", notice)
+	}
+	g.reset()
+	g.netNotice = notice
+}
+
+// pollOnline handles the messages that arrived since the last frame. It
+// returns false once the session has ended.
+func (g *Game) pollOnline() bool {
+	messages, err := g.net.inbox.drain()
+	for _, data := range messages {
+		message, err := g.net.handle(data)
+		if errors.Is(err, errDesync) {
+			g.endOnline("netplay.desync", err)
+			return false
+		}
+		if err != nil {
+			log.Printf("netplay: %v", err)
+			continue
+		}
+		switch message.Type {
+		case "hello":
+			g.beginOnline(message.Player, message.Seed)
+		case "left":
+			g.endOnline("netplay.left", nil)
+			return false
+		}
+	}
+	if err != nil {
+		g.endOnline("netplay.failed", err)
+		return false
+	}
+	return true
+}
+
+// beginOnline starts the shared run once the relay has paired the clients.
+// Both run the original waves at Normal difficulty with the same seed.
+func (g *Game) beginOnline(player int, seed int64) {
+	log.Printf("netplay: paired as player %d in room %s", player+1, g.net.online.roomName())
+	g.net.start(player)
+	g.mode = modeWaves
+	g.world = g.newWorld(seed, true)
+	g.assisted = false
+	g.state = statePlaying
+	g.startTelemetry()
+	g.applyGameSpeed()
+	g.music.start()
+}
+
+// advanceOnline sends the local input and reports whether the next tick can
+// run. Without the partner's input the game holds the frame.
+func (g *Game) advanceOnline() bool {
+	if !g.pollOnline() {
+		return false
+	}
+	session := g.net
+	controls := g.localControls()
+	session.special = session.special || controls.Special
+	if err := session.queueLocal(controls); err != nil {
+		g.endOnline("netplay.failed", err)
+		return false
+	}
+	return session.ready()
+}
+
+// finishOnlineTick moves to the next tick and now and then compares state
+// hashes with the partner.
+func (g *Game) finishOnlineTick() {
+	session := g.net
+	if session == nil {
+		return
+	}
+	tick := session.tick
+	session.tick++
+	if tick%netHashInterval != 0 {
+		return
+	}
+	hash := g.world.Hash()
+	if err := session.recordHash(session.local, tick, hash); err != nil {
+		g.endOnline("netplay.desync", err)
+		return
+	}
+	if err := session.send(netMessage{Type: "hash", Tick: tick, Hash: hash}); err != nil {
+		g.endOnline("netplay.failed", err)
+	}
+}
+
+// updateConnecting waits for the relay to pair this client with a partner.
+func (g *Game) updateConnecting() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || g.touchJustPressed() {
+		log.Printf("netplay: cancelled")
+		g.reset()
+		return
+	}
+	g.pollOnline()
+}
+
+func (g *Game) drawConnecting(screen *ebiten.Image) {
+	g.drawCenteredText(screen, g.message("netplay.waiting", g.net.online.Room), screenHeight/2-20, color.White)
+	g.drawCenteredText(screen, g.net.address, screenHeight/2+20, color.RGBA{R: 130, G: 220, B: 255, A: 255})
+	g.drawCenteredText(screen, g.message("netplay.cancel"), screenHeight/2+60, color.White)
+}
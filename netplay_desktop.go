@@ -0,0 +1,73 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/Kenshu-Miura/mygame/internal/websocket"
+)
+
+// relayURL is the relay to join, overridable with MYGAME_RELAY.
+func relayURL() string {
+	if address := os.Getenv("MYGAME_RELAY"); address != "" {
+		return address
+	}
+	return defaultRelayURL
+}
+
+// socketTransport dials the relay in the background so the window keeps
+// drawing while it connects.
+type socketTransport struct {
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	closed bool
+}
+
+func dialRelay(address string, inbox *netInbox) netTransport {
+	transport := &socketTransport{}
+	go func() {
+		conn, err := websocket.Dial(address)
+		if err != nil {
+			inbox.fail(err)
+			return
+		}
+		transport.mu.Lock()
+		if transport.closed {
+			transport.mu.Unlock()
+			conn.Close()
+			return
+		}
+		transport.conn = conn
+		transport.mu.Unlock()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				inbox.fail(err)
+				return
+			}
+			inbox.push(data)
+		}
+	}()
+	return transport
+}
+
+func (transport *socketTransport) Send(data []byte) error {
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if transport.conn == nil {
+		return errors.New("not connected")
+	}
+	return transport.conn.WriteMessage(data)
+}
+
+func (transport *socketTransport) Close() {
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	transport.closed = true
+	if transport.conn != nil {
+		transport.conn.Close()
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+type fakeTransport struct {
+	sent []netMessage
+}
+
+func (transport *fakeTransport) Send(data []byte) error {
+	var message netMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+	transport.sent = append(transport.sent, message)
+	return nil
+}
+
+func (transport *fakeTransport) Close() {}
+
+func newTestSession(player int) (*netSession, *fakeTransport) {
+	transport := &fakeTransport{}
+	session := newNetSession(transport, &netInbox{}, defaultOnlineSettings(), defaultRelayURL)
+	session.start(player)
+	return session, transport
+}
+
+func TestControlsRoundTripThroughInputBits(t *testing.T) {
+	for bits := range uint8(16) {
+		if got := encodeControls(decodeControls(bits)); got != bits {
+			t.Fatalf("encodeControls(decodeControls(%04b)) = %04b", bits, got)
+		}
+	}
+}
+
+func TestLockstepWaitsForPartnerInput(t *testing.T) {
+	session, transport := newTestSession(sim.PlayerOne)
+	for tick := range netInputDelay {
+		if err := session.queueLocal(sim.Controls{Fire: true}); err != nil {
+			t.Fatal(err)
+		}
+		if !session.ready() {
+			t.Fatalf("tick %d waited, but the first ticks run without input", tick)
+		}
+		session.tick++
+	}
+	if len(transport.sent) != netInputDelay || transport.sent[0].Tick != netInputDelay {
+		t.Fatalf("sent %+v, want inputs from tick %d on", transport.sent, netInputDelay)
+	}
+
+	session.queueLocal(sim.Controls{})
+	if session.ready() {
+		t.Fatal("tick ran before the partner's input arrived")
+	}
+	if _, err := session.handle([]byte(`{"type":"input","tick":3,"input":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !session.ready() {
+		t.Fatal("tick still waiting after the partner's input arrived")
+	}
+	if !session.current[sim.PlayerOne].Fire || !session.current[sim.PlayerTwo].Left {
+		t.Fatalf("current = %+v, want player one firing and player two moving left", session.current)
+	}
+}
+
+func TestLatchedSpecialIsSentOnce(t *testing.T) {
+	session, transport := newTestSession(sim.PlayerTwo)
+	session.special = true
+	session.queueLocal(sim.Controls{})
+	session.tick++
+	session.queueLocal(sim.Controls{})
+	if len(transport.sent) != 2 || !decodeControls(transport.sent[0].Input).Special || decodeControls(transport.sent[1].Input).Special {
+		t.Fatalf("sent %+v, want the KIEE press in the first input only", transport.sent)
+	}
+}
+
+func TestStateHashMismatchIsDesync(t *testing.T) {
+	session, _ := newTestSession(sim.PlayerOne)
+	if err := session.recordHash(sim.PlayerOne, 60, 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.handle([]byte(`{"type":"hash","tick":60,"hash":7}`)); err != nil {
+		t.Fatalf("matching hashes reported %v", err)
+	}
+	session.recordHash(sim.PlayerOne, 120, 7)
+	if _, err := session.handle([]byte(`{"type":"hash","tick":120,"hash":8}`)); !errors.Is(err, errDesync) {
+		t.Fatalf("err = %v, want a desync", err)
+	}
+}
+
+func TestStateHashFollowsTheSimulation(t *testing.T) {
+	first, second := &sim.World{Wave: 2}, &sim.World{Wave: 2}
+	if first.Hash() != second.Hash() {
+		t.Fatal("identical games hashed differently")
+	}
+	second.Pilots[sim.PlayerTwo].Position.X = 0.5
+	if first.Hash() == second.Hash() {
+		t.Fatal("hash ignored player two's position")
+	}
+}
+
+func TestRelayAddressKeepsRulesInSeparateRooms(t *testing.T) {
+	if got := relayAddress(defaultRelayURL, onlineSettings{Room: 2}); got != "ws://localhost:8765/relay?room=coop-2" {
+		t.Fatalf("co-op address = %q", got)
+	}
+	if got := relayAddress(defaultRelayURL, onlineSettings{Versus: true, Room: 3}); got != "ws://localhost:8765/relay?room=versus-3" {
+		t.Fatalf("versus address = %q", got)
+	}
+}
+
+func TestOnlineGamesIgnoreLocalRules(t *testing.T) {
+	g := &Game{net: &netSession{}, debug: true}
+	g.settings.Assist.AutoFire = true
+	g.settings.Difficulty = sim.DifficultyLunatic
+	g.settings.AdaptiveDifficulty = true
+	g.settings.Assist.Invincible = true
+	world := g.newWorld(1, true)
+	if world.Difficulty != sim.DifficultyNormal || world.Adaptive || world.Invincible || g.assist().AutoFire || g.ranked() {
+		t.Fatal("online game used the local difficulty, assists or debug invincibility, or counted for high scores")
+	}
+}
+
+func TestOnlineGamesIgnoreLocalTuning(t *testing.T) {
+	saved := tuning
+	t.Cleanup(func() { tuning = saved })
+	tuning.PlayerSpeed = 99
+	g := &Game{net: &netSession{}}
+	if world := g.newWorld(1, true); world.Tuning != sim.DefaultTuning() {
+		t.Fatalf("online tuning = %+v, want the defaults", world.Tuning)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+// relayURL is the relay to join, overridable with the relay query parameter.
+func relayURL() string {
+	params := js.Global().Get("URLSearchParams").New(js.Global().Get("location").Get("search"))
+	if address := params.Call("get", "relay"); address.Type() == js.TypeString && address.String() != "" {
+		return address.String()
+	}
+	return defaultRelayURL
+}
+
+// browserTransport uses the browser's WebSocket. Its callbacks run between
+// frames and only push to the inbox.
+type browserTransport struct {
+	socket    js.Value
+	callbacks []js.Func
+}
+
+func dialRelay(address string, inbox *netInbox) (transport netTransport) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			inbox.fail(fmt.Errorf("open WebSocket: %v", recovered))
+			transport = &browserTransport{}
+		}
+	}()
+	socket := js.Global().Get("WebSocket").New(address)
+	browser := &browserTransport{socket: socket}
+	onMessage := js.FuncOf(func(this js.Value, args []js.Value) any {
+		inbox.push([]byte(args[0].Get("data").String()))
+		return nil
+	})
+	onClose := js.FuncOf(func(this js.Value, args []js.Value) any {
+		inbox.fail(errors.New("connection closed"))
+		return nil
+	})
+	socket.Set("onmessage", onMessage)
+	socket.Set("onclose", onClose)
+	browser.callbacks = []js.Func{onMessage, onClose}
+	return browser
+}
+
+func (transport *browserTransport) Send(data []byte) error {
+	if transport.socket.IsUndefined() || transport.socket.Get("readyState").Int() != 1 {
+		return errors.New("not connected")
+	}
+	transport.socket.Call("send", string(data))
+	return nil
+}
+
+func (transport *browserTransport) Close() {
+	if transport.socket.IsUndefined() {
+		return
+	}
+	transport.socket.Set("onmessage", js.Null())
+	transport.socket.Set("onclose", js.Null())
+	transport.socket.Call("close")
+	for _, callback := range transport.callbacks {
+		callback.Release()
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const (
+	maxPracticeWave  = 6 * sim.BossWaveCycle
+	practiceKIEEStep = 5
+)
+
+// practiceSettings are the starting conditions of a practice run. They are
+// saved so testers can repeat the same setup.
+type practiceSettings struct {
+	Wave    int  `json:"wave"`
+	PowerUp bool `json:"powerUp"`
+	KIEE    int  `json:"kiee"`
+}
+
+func defaultPracticeSettings() practiceSettings {
+	return practiceSettings{Wave: 1}
+}
+
+func (practice practiceSettings) clamped() practiceSettings {
+	practice.Wave = min(maxPracticeWave, max(1, practice.Wave))
+	practice.KIEE = min(sim.SpecialCost, max(0, practice.KIEE/practiceKIEEStep*practiceKIEEStep))
+	return practice
+}
+
+// openPractice shows the practice setup instead of starting a run straight
+// from the title.
+func (g *Game) openPractice() {
+	g.menu = &menu{title: "practice.title", items: g.practiceMenuItems()}
+	g.state = stateSettings
+}
+
+func (g *Game) practiceMenuItems() []menuItem {
+	practice := &g.settings.Practice
+	return []menuItem{
+		{
+			label: "practice.wave",
+			value: func() string { return fmt.Sprintf("< %2d >", practice.clamped().Wave) },
+			adjust: func(delta int) {
+				practice.Wave = min(maxPracticeWave, max(1, practice.clamped().Wave+delta))
+				g.saveSettings()
+			},
+		},
+		{
+			label: "practice.boss",
+			value: func() string {
+				wave := practice.clamped().Wave
+				if !sim.IsBossWave(wave) {
+					return g.message("practice.noBoss")
+				}
+				return g.message("practice.bossNumber", wave/sim.BossWaveCycle)
+			},
+			adjust: func(delta int) {
+				boss := practice.clamped().Wave / sim.BossWaveCycle
+				if delta < 0 && !sim.IsBossWave(practice.clamped().Wave) {
+					boss++
+				}
+				boss = min(maxPracticeWave/sim.BossWaveCycle, max(1, boss+delta))
+				practice.Wave = boss * sim.BossWaveCycle
+				g.saveSettings()
+			},
+		},
+		g.toggleItem("practice.powerUp", &practice.PowerUp),
+		{
+			label: "practice.kiee",
+			value: func() string { return fmt.Sprintf("< %2d/%d >", practice.clamped().KIEE, sim.SpecialCost) },
+			adjust: func(delta int) {
+				practice.KIEE = min(sim.SpecialCost, max(0, practice.clamped().KIEE+delta*practiceKIEEStep))
+				g.saveSettings()
+			},
+		},
+		{
+			label: "practice.start",
+			value: func() string { return ">" },
+			adjust: func(delta int) {
+				if delta > 0 {
+					g.menu = nil
+					g.startRun()
+				}
+			},
+		},
+	}
+}
+
+// startPractice applies the practice setup to a fresh run.
+func (g *Game) startPractice() {
+	practice := g.settings.Practice.clamped()
+	if practice.Wave != g.world.Wave {
+		g.world.StartWave(practice.Wave)
+	}
+	if practice.PowerUp {
+		g.world.PowerUpTicks = tuning.PowerUpDuration
+	}
+	g.world.Pilots[sim.PlayerOne].MissCount = practice.KIEE
+}
+
+// restartPractice replaces the game over of a practice run with an instant
+// retry from the same setup. The session carries on: the music keeps
+// playing and the run hooks (stats, telemetry) are not started again.
+func (g *Game) restartPractice() {
+	log.Printf("practice: restarting from wave %d", g.settings.Practice.clamped().Wave)
+	g.world = g.newWorld(time.Now().UnixNano(), g.world.Coop)
+	g.startPractice()
+	g.waveBannerTicks = waveBannerTime
+	g.flashTicks = 0
+	g.captions = nil
+	g.touch = touchGesture{}
+	g.progress = achievementProgress{}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+func TestStartPracticeAppliesSetup(t *testing.T) {
+	g := &Game{}
+	g.settings.Mode = modePractice
+	g.settings.Practice = practiceSettings{Wave: 3, PowerUp: true, KIEE: 10}
+	g.startRun()
+	world := g.world
+	if world.Wave != 3 || world.PowerUpTicks != tuning.PowerUpDuration || world.Pilots[sim.PlayerOne].MissCount != 10 {
+		t.Fatalf("wave = %d powerUpTicks = %d missCount = %d, want wave 3 with a power-up and 10 KIEE", world.Wave, world.PowerUpTicks, world.Pilots[sim.PlayerOne].MissCount)
+	}
+}
+
+func TestPracticeRestartKeepsTheSessionGoing(t *testing.T) {
+	sink := &fakeTelemetrySink{}
+	g := &Game{telemetrySink: sink}
+	g.settings.Mode = modePractice
+	g.settings.Telemetry = true
+	g.settings.Practice = practiceSettings{Wave: 3, KIEE: 10}
+	g.startRun()
+	g.world.StartWave(4)
+	g.world.Over = true
+	g.flashTicks = 5
+	g.gameOver()
+	world := g.world
+	if g.state != statePlaying || world.Over || world.Wave != 3 || world.Pilots[sim.PlayerOne].MissCount != 10 {
+		t.Fatalf("state = %v over = %v wave = %d, want a fresh wave 3 to keep playing", g.state, world.Over, world.Wave)
+	}
+	if g.telemetry == nil || len(sink.records) != 1 || g.flashTicks != 0 {
+		t.Fatalf("telemetry records = %v flash = %d, want the session's telemetry still running and the flash cleared", sink.records, g.flashTicks)
+	}
+}
+
+func TestPracticeRunsDoNotSetHighScores(t *testing.T) {
+	store := &fakeHighScoreStore{}
+	g := &Game{world: &sim.World{Score: 25}, highScore: 10, highScoreStore: store, mode: modePractice}
+	g.updateHighScore()
+	if g.highScore != 10 || len(store.saved) != 0 {
+		t.Fatalf("high score = %d with saves %v, want the old record untouched", g.highScore, store.saved)
+	}
+}
+
+func TestPracticeBossSelectJumpsBetweenBossWaves(t *testing.T) {
+	g := &Game{}
+	g.settings.Practice.Wave = 7
+	var boss menuItem
+	for _, item := range g.practiceMenuItems() {
+		if item.label == "practice.boss" {
+			boss = item
+		}
+	}
+	boss.adjust(1)
+	if g.settings.Practice.Wave != 2*sim.BossWaveCycle {
+		t.Fatalf("wave = %d, want the next boss at %d", g.settings.Practice.Wave, 2*sim.BossWaveCycle)
+	}
+	boss.adjust(-1)
+	if g.settings.Practice.Wave != sim.BossWaveCycle {
+		t.Fatalf("wave = %d, want the previous boss at %d", g.settings.Practice.Wave, sim.BossWaveCycle)
+	}
+	boss.adjust(-1)
+	if g.settings.Practice.Wave != sim.BossWaveCycle {
+		t.Fatalf("wave = %d, want the first boss kept", g.settings.Practice.Wave)
+	}
+	for range 10 {
+		boss.adjust(1)
+	}
+	if g.settings.Practice.Wave != maxPracticeWave {
+		t.Fatalf("wave = %d, want it capped at %d", g.settings.Practice.Wave, maxPracticeWave)
+	}
+}
+
+func TestPracticeSettingsClamp(t *testing.T) {
+	got := practiceSettings{Wave: 99, KIEE: 13}.clamped()
+	if want := (practiceSettings{Wave: maxPracticeWave, KIEE: 10}); got != want {
+		t.Fatalf("clamped = %+v, want %+v", got, want)
+	}
+}
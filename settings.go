@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const settingsSaveName = "settings"
+
+// settings are the player's preferences. They are saved as JSON, so fields
+// added later fall back to their defaults when an older file is loaded.
+type settings struct {
+	Audio              audioSettings         `json:"audio"`
+	Language           language              `json:"language"`
+	HUDScale           int                   `json:"hudScale"` // Percent, one of hudScales.
+	Display            displaySettings       `json:"display"`
+	Accessibility      accessibilitySettings `json:"accessibility"`
+	Assist             assistSettings        `json:"assist"`
+	Difficulty         sim.Difficulty        `json:"difficulty"`
+	AdaptiveDifficulty bool                  `json:"adaptiveDifficulty"` // Lets the director nudge spawn rates and speeds.
+	Mode               gameMode              `json:"mode"`
+	Practice           practiceSettings      `json:"practice"`
+	Coop               coopSettings          `json:"coop"`
+	Online             onlineSettings        `json:"online"`
+	Ghost              bool                  `json:"ghost"`
+	Telemetry          bool                  `json:"telemetry"` // Opt-in run log for balance testing.
+}
+
+func defaultSettings() settings {
+	return settings{
+		Audio:         defaultAudioSettings(),
+		HUDScale:      defaultHUDScale,
+		Display:       defaultDisplaySettings(),
+		Accessibility: defaultAccessibilitySettings(),
+		Assist:        defaultAssistSettings(),
+		Practice:      defaultPracticeSettings(),
+		Online:        defaultOnlineSettings(),
+	}
+}
+
+type settingsStore interface {
+	Load() (settings, error)
+	Save(settings settings) error
+}
+
+type savedSettingsStore struct{}
+
+func newSettingsStore() settingsStore {
+	return savedSettingsStore{}
+}
+
+func (savedSettingsStore) Load() (settings, error) {
+	data, err := readSaveData(settingsSaveName)
+	if err != nil || data == nil {
+		return defaultSettings(), err
+	}
+	return decodeSettings(data)
+}
+
+func decodeSettings(data []byte) (settings, error) {
+	decoded := defaultSettings()
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return defaultSettings(), fmt.Errorf("parse settings: %w", err)
+	}
+	decoded.Audio = decoded.Audio.clamped()
+	decoded.HUDScale = clampHUDScale(decoded.HUDScale)
+	decoded.Display = decoded.Display.clamped()
+	decoded.Accessibility = decoded.Accessibility.clamped()
+	decoded.Assist = decoded.Assist.clamped()
+	decoded.Practice = decoded.Practice.clamped()
+	decoded.Online = decoded.Online.clamped()
+	if !knownGameMode(decoded.Mode) {
+		decoded.Mode = modeWaves
+	}
+	if !sim.KnownDifficulty(decoded.Difficulty) {
+		decoded.Difficulty = sim.DifficultyNormal
+	}
+	if !knownLanguage(decoded.Language) {
+		decoded.Language = languageAuto
+	}
+	return decoded, nil
+}
+
+func (savedSettingsStore) Save(settings settings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return writeSaveData(settingsSaveName, data)
+}
+
+func (g *Game) saveSettings() {
+	if g.settingsStore == nil {
+		return
+	}
+	if err := g.settingsStore.Save(g.settings); err != nil {
+		log.Printf("save settings: %v", err)
+	}
+}
+
+func (g *Game) openSettings() {
+	items := append(g.audioMenuItems(),
+		menuItem{
+			label:  "settings.language",
+			value:  g.languageLabel,
+			adjust: g.cycleLanguage,
+		},
+		g.adaptiveDifficultyMenuItem(),
+		g.ghostMenuItem(),
+		g.submenuItem("settings.coop", g.coopMenuItems),
+		g.submenuItem("settings.online", g.onlineMenuItems),
+		g.submenuItem("settings.display", g.displayMenuItems),
+		g.submenuItem("settings.accessibility", g.accessibilityMenuItems),
+		g.submenuItem("settings.assist", g.assistMenuItems),
+		g.submenuItem("settings.telemetry", g.telemetryMenuItems),
+	)
+	g.menu = &menu{title: "settings.title", items: items}
+	g.state = stateSettings
+}
+
+func (g *Game) displayMenuItems() []menuItem {
+	return []menuItem{
+		{
+			label:  "settings.hudScale",
+			value:  func() string { return fmt.Sprintf("< %3d%% >", clampHUDScale(g.settings.HUDScale)) },
+			adjust: g.cycleHUDScale,
+		},
+		{
+			label:  "settings.scale",
+			value:  g.scaleModeLabel,
+			adjust: g.cycleScaleMode,
+		},
+		{
+			label:  "settings.fullscreen",
+			value:  func() string { return g.onOff(g.settings.Display.Fullscreen) },
+			adjust: func(int) { g.toggleFullscreen() },
+		},
+	}
+}
+
+func (g *Game) audioMenuItems() []menuItem {
+	volumeItem := func(label string, volume *int) menuItem {
+		return menuItem{
+			label: label,
+			value: func() string { return fmt.Sprintf("< %3d%% >", *volume) },
+			adjust: func(delta int) {
+				*volume = clampVolume(*volume + delta*volumeStep)
+				g.saveSettings()
+			},
+		}
+	}
+	return []menuItem{
+		volumeItem("settings.masterVolume", &g.settings.Audio.Master),
+		volumeItem("settings.bgmVolume", &g.settings.Audio.BGM),
+		volumeItem("settings.sfxVolume", &g.settings.Audio.SFX),
+		{
+			label:  "settings.mute",
+			value:  func() string { return g.onOff(g.settings.Audio.Muted) },
+			adjust: func(int) { g.toggleMute() },
+		},
+	}
+}
+
+func (g *Game) onOff(enabled bool) string {
+	if enabled {
+		return g.message("common.on")
+	}
+	return g.message("common.off")
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestDecodeSettingsFillsMissingFieldsWithDefaults(t *testing.T) {
+	decoded, err := decodeSettings([]byte(`{"audio":{"bgm":30,"muted":true}}`))
+	if err != nil {
+		t.Fatalf("decode settings: %v", err)
+	}
+	want := defaultSettings()
+	want.Audio.BGM = 30
+	want.Audio.Muted = true
+	if decoded != want {
+		t.Fatalf("settings = %+v, want %+v", decoded, want)
+	}
+
+	if _, err := decodeSettings([]byte(`{"audio":`)); err == nil {
+		t.Fatal("decoding a truncated file succeeded, want an error")
+	}
+}
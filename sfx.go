@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+const (
+	pitchVariantCount = 5
+	pitchJitter       = 0.06 // Variants span ±6% around the recorded pitch.
+	volumeJitter      = 0.15 // Each play is up to 15% quieter than full volume.
+	bytesPerFrame     = 4    // 16-bit stereo PCM.
+)
+
+// voicePlayer is the part of *audio.Player a voice pool needs. Tests replace
+// it to check voice allocation without an audio device.
+type voicePlayer interface {
+	Play()
+	Pause()
+	IsPlaying() bool
+	SetVolume(volume float64)
+	Close() error
+}
+
+type voice struct {
+	player voicePlayer
+	gain   float64
+}
+
+// soundEffect plays one decoded sound on a pool of voices so rapid repeats
+// overlap instead of rewinding each other. When every voice is busy the
+// oldest one is stolen.
+type soundEffect struct {
+	variants  [][]byte
+	voices    []voice
+	maxVoices int
+	vary      bool
+	random    *rand.Rand
+	newPlayer func(pcm []byte) voicePlayer
+}
+
+func newSoundEffect(context *audio.Context, pcm []byte, maxVoices int, vary bool, random *rand.Rand) *soundEffect {
+	effect := &soundEffect{
+		variants:  [][]byte{pcm},
+		maxVoices: max(1, maxVoices),
+		vary:      vary,
+		random:    random,
+		newPlayer: func(pcm []byte) voicePlayer {
+			return context.NewPlayerFromBytes(pcm)
+		},
+	}
+	if vary {
+		effect.variants = effect.variants[:0]
+		for _, ratio := range pitchVariantRatios() {
+			effect.variants = append(effect.variants, resamplePCM(pcm, ratio))
+		}
+	}
+	return effect
+}
+
+// pitchVariantRatios spreads pitchVariantCount playback rates evenly across
+// ±pitchJitter, including the original pitch in the middle.
+func pitchVariantRatios() []float64 {
+	ratios := make([]float64, pitchVariantCount)
+	for index := range ratios {
+		ratios[index] = 1 - pitchJitter + 2*pitchJitter*float64(index)/float64(pitchVariantCount-1)
+	}
+	return ratios
+}
+
+// resamplePCM changes the pitch of 16-bit stereo PCM by playing it back ratio
+// times faster, using linear interpolation between frames.
+func resamplePCM(pcm []byte, ratio float64) []byte {
+	frames := len(pcm) / bytesPerFrame
+	if frames < 2 || ratio == 1 {
+		return pcm
+	}
+	outFrames := int(float64(frames-1)/ratio) + 1
+	out := make([]byte, outFrames*bytesPerFrame)
+	sample := func(frame, channel int) float64 {
+		offset := frame*bytesPerFrame + channel*2
+		return float64(int16(binary.LittleEndian.Uint16(pcm[offset:])))
+	}
+	for frame := range outFrames {
+		position := float64(frame) * ratio
+		left := min(frames-1, int(position))
+		right := min(frames-1, left+1)
+		weight := position - float64(left)
+		for channel := range 2 {
+			value := sample(left, channel)*(1-weight) + sample(right, channel)*weight
+			binary.LittleEndian.PutUint16(out[frame*bytesPerFrame+channel*2:], uint16(int16(math.Round(value))))
+		}
+	}
+	return out
+}
+
+func (effect *soundEffect) play(volume float64) {
+	if effect == nil {
+		return
+	}
+	effect.releaseFinishedVoices()
+	if len(effect.voices) >= effect.maxVoices {
+		effect.closeVoice(0)
+	}
+
+	pcm := effect.variants[len(effect.variants)/2]
+	gain := 1.0
+	if effect.vary {
+		pcm = effect.variants[effect.random.Intn(len(effect.variants))]
+		gain = 1 - volumeJitter*effect.random.Float64()
+	}
+	player := effect.newPlayer(pcm)
+	player.SetVolume(volume * gain)
+	player.Play()
+	effect.voices = append(effect.voices, voice{player: player, gain: gain})
+}
+
+func (effect *soundEffect) releaseFinishedVoices() {
+	for index := len(effect.voices) - 1; index >= 0; index-- {
+		if !effect.voices[index].player.IsPlaying() {
+			effect.closeVoice(index)
+		}
+	}
+}
+
+func (effect *soundEffect) closeVoice(index int) {
+	player := effect.voices[index].player
+	player.Pause()
+	if err := player.Close(); err != nil {
+		log.Printf("close sound voice: %v", err)
+	}
+	effect.voices = removeAt(effect.voices, index)
+}
+
+// setVolume applies a new channel volume to the voices that are still playing.
+func (effect *soundEffect) setVolume(volume float64) {
+	if effect == nil {
+		return
+	}
+	for _, voice := range effect.voices {
+		voice.player.SetVolume(volume * voice.gain)
+	}
+}
+
+func (effect *soundEffect) isPlaying() bool {
+	if effect == nil {
+		return false
+	}
+	for _, voice := range effect.voices {
+		if voice.player.IsPlaying() {
+			return true
+		}
+	}
+	return false
+}
+
+func (effect *soundEffect) stop() {
+	if effect == nil {
+		return
+	}
+	for len(effect.voices) > 0 {
+		effect.closeVoice(len(effect.voices) - 1)
+	}
+}
+
+func (g *Game) playSound(effect *soundEffect) {
+	effect.play(g.settings.Audio.volume(channelSFX))
+}
+
+// soundEvent plays the sound effects for gameplay events.
+func (g *Game) soundEvent(event sim.Event) {
+	switch event := event.(type) {
+	case sim.ShotFired:
+		g.playSound(g.shotSound)
+	case sim.UFODestroyed:
+		if !event.Special {
+			g.playSound(g.hitSound)
+		}
+	case sim.BossDamaged:
+		if !event.Special {
+			g.playSound(g.hitSound)
+		}
+	case sim.EbiHit:
+		g.playSound(g.hoaaSound)
+		g.playSound(g.hitSound)
+	case sim.BossDefeated:
+		g.music.playSting(g.settings.Audio.volume(channelBGM))
+	case sim.PlayerDied:
+		if event.Down {
+			g.playSound(g.hoaaSound)
+		}
+	case sim.PlayerRevived:
+		g.playSound(g.hitSound)
+	case sim.SpecialUsed:
+		g.playSound(g.kieeSound)
+		g.playSound(g.kieeSound2)
+	}
+}
+
+func loadSoundEffect(context *audio.Context, asset soundAsset, random *rand.Rand) (*soundEffect, error) {
+	data, err := readAsset(asset.path)
+	if err != nil {
+		return nil, fmt.Errorf("open sound %q: %w", asset.path, err)
+	}
+
+	stream, err := wav.DecodeWithSampleRate(audioSampleRate, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode sound %q: %w", asset.path, err)
+	}
+	pcm, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, fmt.Errorf("decode sound %q: %w", asset.path, err)
+	}
+	return newSoundEffect(context, pcm, asset.maxVoices, asset.vary, random), nil
+}
+
+// loadSoundEffectOrSynth falls back to the asset's synthesized sound when its
+// file is missing, or always when synthesized is set.
+func loadSoundEffectOrSynth(context *audio.Context, asset soundAsset, random *rand.Rand, synthesized bool) (*soundEffect, error) {
+	if !synthesized {
+		effect, err := loadSoundEffect(context, asset, random)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return effect, err
+		}
+		log.Printf("%v; using a synthesized sound", err)
+	}
+	return newSoundEffect(context, asset.synth(), asset.maxVoices, asset.vary, random), nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+type fakeVoicePlayer struct {
+	playing bool
+	closed  bool
+	volume  float64
+}
+
+func (player *fakeVoicePlayer) Play()                    { player.playing = true }
+func (player *fakeVoicePlayer) Pause()                   { player.playing = false }
+func (player *fakeVoicePlayer) IsPlaying() bool          { return player.playing }
+func (player *fakeVoicePlayer) SetVolume(volume float64) { player.volume = volume }
+func (player *fakeVoicePlayer) Close() error {
+	player.closed = true
+	return nil
+}
+
+func newFakeSoundEffect(maxVoices int, vary bool) (*soundEffect, *[]*fakeVoicePlayer) {
+	created := &[]*fakeVoicePlayer{}
+	pcm := make([]byte, 16*bytesPerFrame)
+	effect := newSoundEffect(nil, pcm, maxVoices, vary, rand.New(rand.NewSource(1)))
+	effect.newPlayer = func([]byte) voicePlayer {
+		player := &fakeVoicePlayer{}
+		*created = append(*created, player)
+		return player
+	}
+	return effect, created
+}
+
+func TestSoundEffectOverlapsUpToMaxVoicesThenStealsOldest(t *testing.T) {
+	effect, created := newFakeSoundEffect(3, false)
+	for range 3 {
+		effect.play(1)
+	}
+	if len(effect.voices) != 3 {
+		t.Fatalf("voices = %d, want 3 overlapping plays", len(effect.voices))
+	}
+
+	effect.play(1)
+	players := *created
+	if len(effect.voices) != 3 {
+		t.Fatalf("voices after stealing = %d, want 3", len(effect.voices))
+	}
+	if !players[0].closed || players[0].playing {
+		t.Fatal("oldest voice was not stolen")
+	}
+	if !players[3].playing {
+		t.Fatal("newest play is not audible")
+	}
+}
+
+func TestSoundEffectReusesFinishedVoices(t *testing.T) {
+	effect, created := newFakeSoundEffect(2, false)
+	effect.play(1)
+	effect.play(1)
+	(*created)[1].playing = false
+
+	effect.play(1)
+	players := *created
+	if players[0].closed {
+		t.Fatal("a playing voice was stolen while a finished one was available")
+	}
+	if !players[1].closed {
+		t.Fatal("finished voice was not released")
+	}
+	if !effect.isPlaying() {
+		t.Fatal("effect should report playing voices")
+	}
+	effect.stop()
+	if effect.isPlaying() || len(effect.voices) != 0 {
+		t.Fatalf("voices after stop = %d, want none", len(effect.voices))
+	}
+}
+
+func TestSoundEffectVariationStaysWithinJitter(t *testing.T) {
+	effect, created := newFakeSoundEffect(100, true)
+	if len(effect.variants) != pitchVariantCount {
+		t.Fatalf("pitch variants = %d, want %d", len(effect.variants), pitchVariantCount)
+	}
+	for range 50 {
+		effect.play(0.8)
+	}
+	seen := map[float64]bool{}
+	for _, player := range *created {
+		if player.volume > 0.8 || player.volume < 0.8*(1-volumeJitter) {
+			t.Fatalf("voice volume = %v, want within %v%% below 0.8", player.volume, volumeJitter*100)
+		}
+		seen[player.volume] = true
+	}
+	if len(seen) < 2 {
+		t.Fatal("volume was not randomized between plays")
+	}
+
+	effect.setVolume(0)
+	for _, player := range *created {
+		if player.volume != 0 {
+			t.Fatalf("voice volume after setVolume(0) = %v", player.volume)
+		}
+	}
+}
+
+func TestResamplePCMChangesLengthAndInterpolates(t *testing.T) {
+	pcm := make([]byte, 3*bytesPerFrame)
+	for frame, value := range []int16{0, 100, 200} {
+		binary.LittleEndian.PutUint16(pcm[frame*bytesPerFrame:], uint16(value))
+		binary.LittleEndian.PutUint16(pcm[frame*bytesPerFrame+2:], uint16(-value))
+	}
+
+	slower := resamplePCM(pcm, 0.5)
+	if frames := len(slower) / bytesPerFrame; frames != 5 {
+		t.Fatalf("half-speed frames = %d, want 5", frames)
+	}
+	left := int16(binary.LittleEndian.Uint16(slower[bytesPerFrame:]))
+	right := int16(binary.LittleEndian.Uint16(slower[bytesPerFrame+2:]))
+	if left != 50 || right != -50 {
+		t.Fatalf("interpolated frame = (%d,%d), want (50,-50)", left, right)
+	}
+
+	faster := resamplePCM(pcm, 2)
+	if frames := len(faster) / bytesPerFrame; frames != 2 {
+		t.Fatalf("double-speed frames = %d, want 2", frames)
+	}
+
+	ratios := pitchVariantRatios()
+	if ratios[0] != 1-pitchJitter || ratios[len(ratios)/2] != 1 {
+		t.Fatalf("pitch ratios = %v, want %v..1..%v", ratios, 1-pitchJitter, 1+pitchJitter)
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const (
+	statsSaveName   = "stats"
+	statsExportName = "mygame-stats.json"
+	maxDeathRows    = 8 // The deaths page lists the waves with the most deaths.
+)
+
+// lifetimeStats add up every run except practice runs, which start anywhere.
+type lifetimeStats struct {
+	PlayTicks   int            `json:"playTicks"`
+	Runs        int            `json:"runs"`
+	UFOs        int            `json:"ufos"`
+	Ebis        int            `json:"ebis"`
+	Bosses      int            `json:"bosses"`
+	Shots       int            `json:"shots"`
+	Hits        int            `json:"hits"` // Shots that hit a UFO or a boss.
+	Specials    int            `json:"specials"`
+	MaxCombo    int            `json:"maxCombo"`
+	HighestWave int            `json:"highestWave"`
+	Deaths      map[string]int `json:"deathsByWave"` // Keyed by wave number.
+}
+
+func (stats lifetimeStats) accuracy() float64 {
+	if stats.Shots == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Shots)
+}
+
+type statsStore interface {
+	Load() (lifetimeStats, error)
+	Save(stats lifetimeStats) error
+}
+
+type savedStatsStore struct{}
+
+func newStatsStore() statsStore {
+	return savedStatsStore{}
+}
+
+func (savedStatsStore) Load() (lifetimeStats, error) {
+	data, err := readSaveData(statsSaveName)
+	if err != nil || data == nil {
+		return lifetimeStats{}, err
+	}
+	var stats lifetimeStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return lifetimeStats{}, fmt.Errorf("parse stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (savedStatsStore) Save(stats lifetimeStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return writeSaveData(statsSaveName, data)
+}
+
+func (g *Game) loadStats() {
+	if g.statsStore == nil {
+		return
+	}
+	stats, err := g.statsStore.Load()
+	if err != nil {
+		log.Printf("load stats: %v", err)
+	}
+	g.stats = stats
+}
+
+// countStats reports whether the current run adds to the lifetime stats.
+// Practice and debug runs skip around the waves and would skew them.
+func (g *Game) countStats() bool {
+	return !g.debug && g.mode != modePractice
+}
+
+// updateStats applies a change to the stats and marks them for saving when
+// the run ends.
+func (g *Game) updateStats(change func(stats *lifetimeStats)) {
+	if !g.countStats() {
+		return
+	}
+	change(&g.stats)
+	g.statsChanged = true
+}
+
+// finishStats saves the stats once per run instead of on every change.
+func (g *Game) finishStats() {
+	if !g.statsChanged {
+		return
+	}
+	g.statsChanged = false
+	if g.statsStore == nil {
+		return
+	}
+	if err := g.statsStore.Save(g.stats); err != nil {
+		log.Printf("save stats: %v", err)
+	}
+}
+
+// statsEvent adds gameplay events to the lifetime stats.
+func (g *Game) statsEvent(event sim.Event) {
+	switch event := event.(type) {
+	case sim.ShotFired:
+		g.statsShots(event.Count)
+	case sim.UFODestroyed:
+		g.statsUFODestroyed()
+		g.statsCombo(g.world.Pilots[event.Shooter].Combo)
+		if !event.Special {
+			g.statsShotHit()
+		}
+	case sim.BossDamaged:
+		g.statsCombo(g.world.Pilots[event.Shooter].Combo)
+		if !event.Special {
+			g.statsShotHit()
+		}
+	case sim.EbiHit:
+		g.statsEbiDestroyed()
+	case sim.BossDefeated:
+		g.statsBossDestroyed()
+	case sim.WaveStarted:
+		g.statsWaveReached(event.Wave)
+	case sim.PlayerDied:
+		g.statsDeath()
+	case sim.SpecialUsed:
+		g.statsSpecialUsed()
+	}
+}
+
+func (g *Game) statsRunStarted() {
+	g.updateStats(func(stats *lifetimeStats) { stats.Runs++ })
+}
+
+func (g *Game) statsTick() {
+	g.updateStats(func(stats *lifetimeStats) { stats.PlayTicks++ })
+}
+
+func (g *Game) statsWaveReached(wave int) {
+	g.updateStats(func(stats *lifetimeStats) { stats.HighestWave = max(stats.HighestWave, wave) })
+}
+
+func (g *Game) statsShots(count int) {
+	g.updateStats(func(stats *lifetimeStats) { stats.Shots += count })
+}
+
+func (g *Game) statsShotHit() {
+	g.updateStats(func(stats *lifetimeStats) { stats.Hits++ })
+}
+
+func (g *Game) statsCombo(combo int) {
+	g.updateStats(func(stats *lifetimeStats) { stats.MaxCombo = max(stats.MaxCombo, combo) })
+}
+
+func (g *Game) statsUFODestroyed() {
+	g.updateStats(func(stats *lifetimeStats) { stats.UFOs++ })
+}
+
+func (g *Game) statsEbiDestroyed() {
+	g.updateStats(func(stats *lifetimeStats) { stats.Ebis++ })
+}
+
+func (g *Game) statsBossDestroyed() {
+	g.updateStats(func(stats *lifetimeStats) { stats.Bosses++ })
+}
+
+func (g *Game) statsSpecialUsed() {
+	g.updateStats(func(stats *lifetimeStats) { stats.Specials++ })
+}
+
+// statsDeath counts a player touching an enemy, including co-op knockdowns.
+func (g *Game) statsDeath() {
+	g.updateStats(func(stats *lifetimeStats) {
+		if stats.Deaths == nil {
+			stats.Deaths = map[string]int{}
+		}
+		stats.Deaths[strconv.Itoa(g.world.Wave)]++
+	})
+}
+
+// playTime formats ticks of play as hours, minutes and seconds.
+func playTime(ticks int) string {
+	seconds := ticks / 60
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+type waveDeaths struct {
+	wave   int
+	deaths int
+}
+
+// deadliestWaves returns up to limit waves with the most deaths, in wave
+// order.
+func deadliestWaves(deaths map[string]int, limit int) []waveDeaths {
+	var waves []waveDeaths
+	for key, count := range deaths {
+		if wave, err := strconv.Atoi(key); err == nil && count > 0 {
+			waves = append(waves, waveDeaths{wave: wave, deaths: count})
+		}
+	}
+	slices.SortFunc(waves, func(a, b waveDeaths) int {
+		return cmp.Or(cmp.Compare(b.deaths, a.deaths), cmp.Compare(a.wave, b.wave))
+	})
+	waves = waves[:min(limit, len(waves))]
+	slices.SortFunc(waves, func(a, b waveDeaths) int { return cmp.Compare(a.wave, b.wave) })
+	return waves
+}
+
+// statsExport is the exported JSON: the saved stats plus the derived values
+// shown on the stats screen.
+type statsExport struct {
+	lifetimeStats
+	PlaySeconds int     `json:"playSeconds"`
+	Accuracy    float64 `json:"accuracy"`
+}
+
+func (g *Game) statsJSON() ([]byte, error) {
+	return json.MarshalIndent(statsExport{
+		lifetimeStats: g.stats,
+		PlaySeconds:   g.stats.PlayTicks / 60,
+		Accuracy:      g.stats.accuracy(),
+	}, "", "  ")
+}
+
+// openStats shows the lifetime stats from the title, split into pages that
+// fit the menu.
+func (g *Game) openStats() {
+	g.menu = &menu{title: "stats.title", items: g.statsMenuItems()}
+	g.state = stateSettings
+}
+
+func statRow(label string, value func() string) menuItem {
+	return menuItem{label: label, value: value, adjust: func(int) {}}
+}
+
+func (g *Game) statsMenuItems() []menuItem {
+	exported := ""
+	return []menuItem{
+		g.submenuItem("stats.runs", func() []menuItem {
+			return []menuItem{
+				statRow("stats.playTime", func() string { return playTime(g.stats.PlayTicks) }),
+				statRow("stats.runCount", func() string { return strconv.Itoa(g.stats.Runs) }),
+				statRow("stats.highestWave", func() string { return strconv.Itoa(g.stats.HighestWave) }),
+				statRow("stats.maxCombo", func() string { return strconv.Itoa(g.stats.MaxCombo) }),
+			}
+		}),
+		g.submenuItem("stats.combat", func() []menuItem {
+			return []menuItem{
+				statRow("stats.ufos", func() string { return strconv.Itoa(g.stats.UFOs) }),
+				statRow("stats.ebis", func() string { return strconv.Itoa(g.stats.Ebis) }),
+				statRow("stats.bosses", func() string { return strconv.Itoa(g.stats.Bosses) }),
+				statRow("stats.shots", func() string { return g.message("stats.shotsValue", g.stats.Hits, g.stats.Shots) }),
+				statRow("stats.accuracy", func() string { return fmt.Sprintf("%.1f%%", 100*g.stats.accuracy()) }),
+				statRow("stats.specials", func() string { return strconv.Itoa(g.stats.Specials) }),
+			}
+		}),
+		g.submenuItem("stats.deaths", func() []menuItem {
+			// The rows are labelled with the formatted wave, which message
+			// returns as is.
+			var items []menuItem
+			for _, row := range deadliestWaves(g.stats.Deaths, maxDeathRows) {
+				items = append(items, statRow(g.message("stats.wave", row.wave), func() string { return strconv.Itoa(row.deaths) }))
+			}
+			if len(items) == 0 {
+				items = append(items, statRow("stats.noDeaths", func() string { return "" }))
+			}
+			return items
+		}),
+		{
+			label: "stats.export",
+			value: func() string {
+				if exported != "" {
+					return exported
+				}
+				return ">"
+			},
+			adjust: func(delta int) {
+				if delta <= 0 {
+					return
+				}
+				data, err := g.statsJSON()
+				if err == nil {
+					err = exportStats(data)
+				}
+				if err != nil {
+					log.Printf("export stats: %v", err)
+					exported = g.message("stats.exportFailed")
+					return
+				}
+				exported = g.message("stats.exported")
+			},
+		},
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !js
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// exportStats writes the stats next to where the game was started.
+func exportStats(data []byte) error {
+	path, err := filepath.Abs(statsExportName)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	log.Printf("exported stats to %s", path)
+	return nil
+}
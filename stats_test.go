@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+type fakeStatsStore struct {
+	stats lifetimeStats
+	saves int
+}
+
+func (store *fakeStatsStore) Load() (lifetimeStats, error) {
+	return store.stats, nil
+}
+
+func (store *fakeStatsStore) Save(stats lifetimeStats) error {
+	store.stats = stats
+	store.saves++
+	return nil
+}
+
+func TestStatsAreSavedOncePerRun(t *testing.T) {
+	store := &fakeStatsStore{stats: lifetimeStats{Runs: 4}}
+	g := &Game{statsStore: store, world: &sim.World{Wave: 3}}
+	g.loadStats()
+	g.statsRunStarted()
+	g.statsShots(3)
+	g.statsShotHit()
+	g.statsCombo(7)
+	g.statsCombo(2)
+	g.statsDeath()
+	if store.saves != 0 {
+		t.Fatalf("saved %d times during the run, want none", store.saves)
+	}
+	g.finishStats()
+	g.finishStats()
+	want := lifetimeStats{Runs: 5, Shots: 3, Hits: 1, MaxCombo: 7, Deaths: map[string]int{"3": 1}}
+	if store.saves != 1 || store.stats.Runs != want.Runs || store.stats.Shots != want.Shots ||
+		store.stats.Hits != want.Hits || store.stats.MaxCombo != want.MaxCombo || store.stats.Deaths["3"] != 1 {
+		t.Fatalf("saves = %d stats = %+v, want one save of %+v", store.saves, store.stats, want)
+	}
+}
+
+func TestPracticeAndDebugRunsDoNotCountStats(t *testing.T) {
+	for _, g := range []*Game{{mode: modePractice}, {debug: true}} {
+		store := &fakeStatsStore{}
+		g.statsStore = store
+		g.statsRunStarted()
+		g.statsUFODestroyed()
+		g.finishStats()
+		if store.saves != 0 || g.stats.Runs != 0 || g.stats.UFOs != 0 {
+			t.Fatalf("saves = %d stats = %+v after a run with mode %q debug=%v", store.saves, g.stats, g.mode, g.debug)
+		}
+	}
+}
+
+func TestDeadliestWaves(t *testing.T) {
+	deaths := map[string]int{"1": 2, "4": 5, "2": 5, "9": 1, "bad": 8, "6": 0}
+	got := deadliestWaves(deaths, 3)
+	want := []waveDeaths{{wave: 1, deaths: 2}, {wave: 2, deaths: 5}, {wave: 4, deaths: 5}}
+	if len(got) != len(want) {
+		t.Fatalf("deadliestWaves = %v, want %v", got, want)
+	}
+	for index := range want {
+		if got[index] != want[index] {
+			t.Fatalf("deadliestWaves = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPlayTime(t *testing.T) {
+	for ticks, want := range map[int]string{0: "0:00:00", 59 * 60: "0:00:59", 3661 * 60: "1:01:01"} {
+		if got := playTime(ticks); got != want {
+			t.Errorf("playTime(%d) = %q, want %q", ticks, got, want)
+		}
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	g := &Game{stats: lifetimeStats{PlayTicks: 600, Shots: 4, Hits: 1, Deaths: map[string]int{"2": 3}}}
+	data, err := g.statsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported struct {
+		PlaySeconds int            `json:"playSeconds"`
+		Accuracy    float64        `json:"accuracy"`
+		Shots       int            `json:"shots"`
+		Deaths      map[string]int `json:"deathsByWave"`
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported.PlaySeconds != 10 || exported.Accuracy != 0.25 || exported.Shots != 4 || exported.Deaths["2"] != 3 {
+		t.Fatalf("exported %s", data)
+	}
+}
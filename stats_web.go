@@ -0,0 +1,8 @@
+//go:build js
+
+package main
+
+// exportStats downloads the stats as a file.
+func exportStats(data []byte) error {
+	return downloadFile(statsExportName, "application/json", data)
+}
@@ -0,0 +1,42 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// saveDataPath places named JSON save files next to the high score in the
+// user's config directory.
+func saveDataPath(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "mygame", name+".json"), nil
+}
+
+// readSaveData returns nil data without an error when nothing was saved yet.
+func readSaveData(name string) ([]byte, error) {
+	path, err := saveDataPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func writeSaveData(name string, data []byte) error {
+	path, err := saveDataPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
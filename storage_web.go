@@ -0,0 +1,58 @@
+//go:build js
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+func saveDataStorageKey(name string) string {
+	return "mygame." + name
+}
+
+// readSaveData returns nil data without an error when nothing was saved yet.
+func readSaveData(name string) (data []byte, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			data = nil
+			err = fmt.Errorf("read localStorage: %v", recovered)
+		}
+	}()
+
+	value := js.Global().Get("localStorage").Call("getItem", saveDataStorageKey(name))
+	if value.IsNull() || value.IsUndefined() {
+		return nil, nil
+	}
+	return []byte(value.String()), nil
+}
+
+func writeSaveData(name string, data []byte) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("write localStorage: %v", recovered)
+		}
+	}()
+
+	js.Global().Get("localStorage").Call("setItem", saveDataStorageKey(name), string(data))
+	return nil
+}
+
+// downloadFile offers data as a file download through a temporary link.
+func downloadFile(name, mimeType string, data []byte) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("download %s: %v", name, recovered)
+		}
+	}()
+
+	parts := js.Global().Get("Array").New(string(data))
+	blob := js.Global().Get("Blob").New(parts, map[string]any{"type": mimeType})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+	defer js.Global().Get("URL").Call("revokeObjectURL", url)
+	link := js.Global().Get("document").Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", name)
+	link.Call("click")
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// The synthesizer generates retro stand-ins for missing sound files. Every
+// sound is deterministic so tests and prototypes always hear the same thing.
+
+type waveform func(phase float64) float64
+
+func squareWave(phase float64) float64 {
+	if math.Mod(phase, 1) < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+func triangleWave(phase float64) float64 {
+	return 4*math.Abs(math.Mod(phase, 1)-0.5) - 1
+}
+
+// noiseSource is a xorshift generator, so noise bursts are reproducible
+// without touching the gameplay random source.
+type noiseSource uint32
+
+func (noise *noiseSource) next() float64 {
+	value := uint32(*noise)
+	value ^= value << 13
+	value ^= value >> 17
+	value ^= value << 5
+	*noise = noiseSource(value)
+	return float64(value)/math.MaxUint32*2 - 1
+}
+
+func synthSampleCount(seconds float64) int {
+	return int(seconds * audioSampleRate)
+}
+
+// decayEnvelope fades from 1 to 0 over the sound with a short attack to
+// avoid clicks.
+func decayEnvelope(index, length int, curve float64) float64 {
+	const attack = 0.004 * audioSampleRate
+	level := math.Pow(1-float64(index)/float64(length), curve)
+	if float64(index) < attack {
+		level *= float64(index) / attack
+	}
+	return level
+}
+
+// synthSweep glides a waveform from one frequency to another.
+func synthSweep(wave waveform, from, to, seconds, volume float64) []float64 {
+	length := synthSampleCount(seconds)
+	samples := make([]float64, length)
+	phase := 0.0
+	for index := range samples {
+		progress := float64(index) / float64(length)
+		frequency := from * math.Pow(to/from, progress)
+		phase += frequency / audioSampleRate
+		samples[index] = wave(phase) * volume * decayEnvelope(index, length, 1.5)
+	}
+	return samples
+}
+
+// synthNoise is a noise burst through a low-pass filter that closes as the
+// sound decays, which reads as an explosion at longer lengths.
+func synthNoise(seconds, volume, brightness float64, seed noiseSource) []float64 {
+	length := synthSampleCount(seconds)
+	samples := make([]float64, length)
+	noise := seed
+	filtered := 0.0
+	for index := range samples {
+		envelope := decayEnvelope(index, length, 2)
+		cutoff := brightness * (0.05 + 0.95*envelope)
+		filtered += (noise.next() - filtered) * cutoff
+		samples[index] = filtered * volume * envelope
+	}
+	return samples
+}
+
+func mixSamples(layers ...[]float64) []float64 {
+	length := 0
+	for _, layer := range layers {
+		length = max(length, len(layer))
+	}
+	mixed := make([]float64, length)
+	for _, layer := range layers {
+		for index, sample := range layer {
+			mixed[index] += sample
+		}
+	}
+	return mixed
+}
+
+// synthNotes plays a melody with one note per step. A zero frequency rests.
+func synthNotes(wave waveform, frequencies []float64, noteSeconds, volume float64) []float64 {
+	noteLength := synthSampleCount(noteSeconds)
+	samples := make([]float64, 0, noteLength*len(frequencies))
+	for _, frequency := range frequencies {
+		phase := 0.0
+		for index := range noteLength {
+			sample := 0.0
+			if frequency > 0 {
+				phase += frequency / audioSampleRate
+				sample = wave(phase) * volume * decayEnvelope(index, noteLength, 0.6)
+			}
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// pcmFromSamples converts mono samples in [-1, 1] to 16-bit stereo PCM.
+func pcmFromSamples(samples []float64) []byte {
+	pcm := make([]byte, len(samples)*bytesPerFrame)
+	for index, sample := range samples {
+		value := uint16(int16(math.Round(min(1, max(-1, sample)) * math.MaxInt16)))
+		binary.LittleEndian.PutUint16(pcm[index*bytesPerFrame:], value)
+		binary.LittleEndian.PutUint16(pcm[index*bytesPerFrame+2:], value)
+	}
+	return pcm
+}
+
+func synthShot() []byte {
+	return pcmFromSamples(synthSweep(squareWave, 1400, 380, 0.12, 0.25))
+}
+
+func synthHit() []byte {
+	return pcmFromSamples(mixSamples(
+		synthNoise(0.16, 0.5, 0.6, 0x1234),
+		synthSweep(squareWave, 240, 90, 0.16, 0.25),
+	))
+}
+
+func synthExplosion() []byte {
+	return pcmFromSamples(mixSamples(
+		synthNoise(0.9, 0.9, 0.35, 0x9e37),
+		synthSweep(triangleWave, 120, 35, 0.9, 0.5),
+	))
+}
+
+// synthBlunder is the disappointed "boo" for shooting a shrimp.
+func synthBlunder() []byte {
+	return pcmFromSamples(synthSweep(triangleWave, 330, 150, 0.35, 0.45))
+}
+
+func synthGameOverJingle() []byte {
+	return pcmFromSamples(synthNotes(squareWave, []float64{523.25, 392.00, 329.63, 261.63, 0, 196.00}, 0.22, 0.22))
+}
+
+func synthVictoryJingle() []byte {
+	return pcmFromSamples(synthNotes(squareWave, []float64{523.25, 659.25, 783.99, 1046.50, 0, 1046.50}, 0.12, 0.22))
+}
+
+// synthBGM is a four-bar chiptune loop used when BGM.ogg is missing.
+func synthBGM() []byte {
+	const step = 60.0 / 140 / 2 // Eighth notes at 140 BPM.
+	bass := []float64{
+		110.00, 0, 110.00, 110.00, 130.81, 0, 130.81, 146.83,
+		87.31, 0, 87.31, 87.31, 98.00, 0, 98.00, 123.47,
+	}
+	lead := []float64{
+		440.00, 523.25, 659.25, 523.25, 440.00, 523.25, 659.25, 783.99,
+		349.23, 440.00, 523.25, 440.00, 392.00, 493.88, 587.33, 493.88,
+	}
+	bassLine := synthNotes(triangleWave, append(bass, bass...), step, 0.35)
+	leadLine := synthNotes(squareWave, append(lead, lead...), step, 0.12)
+	return pcmFromSamples(mixSamples(bassLine, leadLine))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestEverySoundAssetHasAudibleSynthFallback(t *testing.T) {
+	g := &Game{}
+	for _, asset := range g.soundAssets() {
+		if asset.synth == nil {
+			t.Fatalf("%s has no synthesized fallback", asset.path)
+		}
+		pcm := asset.synth()
+		if len(pcm) == 0 || len(pcm)%bytesPerFrame != 0 {
+			t.Fatalf("%s synth length = %d bytes, want whole stereo frames", asset.path, len(pcm))
+		}
+		peak := 0
+		for offset := 0; offset < len(pcm); offset += 2 {
+			peak = max(peak, int(math.Abs(float64(int16(binary.LittleEndian.Uint16(pcm[offset:]))))))
+		}
+		if peak < math.MaxInt16/10 {
+			t.Fatalf("%s synth peak = %d, want an audible sound", asset.path, peak)
+		}
+		if !bytes.Equal(pcm, asset.synth()) {
+			t.Fatalf("%s synth is not deterministic", asset.path)
+		}
+	}
+}
+
+func TestPCMFromSamplesClipsAndDuplicatesChannels(t *testing.T) {
+	pcm := pcmFromSamples([]float64{2, -2, 0.5})
+	want := []int16{math.MaxInt16, math.MaxInt16, -math.MaxInt16, -math.MaxInt16, 16384, 16384}
+	for index, value := range want {
+		if got := int16(binary.LittleEndian.Uint16(pcm[index*2:])); got != value {
+			t.Fatalf("sample %d = %d, want %d", index, got, value)
+		}
+	}
+}
+
+func TestSynthBGMLoopsOnWholeBars(t *testing.T) {
+	const bar = 4 * 60.0 / 140 // Four beats at 140 BPM.
+	seconds := float64(len(synthBGM())/bytesPerFrame) / audioSampleRate
+	if bars := seconds / bar; math.Abs(bars-math.Round(bars)) > 0.01 {
+		t.Fatalf("synthesized BGM is %.3f bars, want a whole number so the loop stays on the beat", bars)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+const telemetryExportName = "mygame-telemetry.jsonl"
+
+// telemetrySink stores the run log, one JSON record per line.
+type telemetrySink interface {
+	Write(record []byte) error
+	// Export copies the log somewhere testers can pick it up.
+	Export() error
+}
+
+// telemetryRecord starts every line of the run log. Tick counts the ticks
+// since the run started.
+type telemetryRecord struct {
+	Type    string `json:"type"`
+	Session string `json:"session"`
+	Run     int    `json:"run"`
+	Tick    int    `json:"tick"`
+}
+
+type telemetryRunStart struct {
+	telemetryRecord
+	Time       string         `json:"time"`
+	Version    string         `json:"version"`
+	Seed       int64          `json:"seed"`
+	Mode       gameMode       `json:"mode"`
+	Difficulty sim.Difficulty `json:"difficulty"`
+	Coop       bool           `json:"coop"`
+	Online     bool           `json:"online"`
+	Assisted   bool           `json:"assisted"`
+}
+
+// telemetryInputs counts the ticks each control was held during a wave and
+// the shots fired.
+type telemetryInputs struct {
+	Left    int `json:"left"`
+	Right   int `json:"right"`
+	Fire    int `json:"fire"`
+	Special int `json:"special"`
+	Shots   int `json:"shots"`
+}
+
+// telemetryWave is written when a wave ends. Cleared is false for the wave
+// the run ended in.
+type telemetryWave struct {
+	telemetryRecord
+	Wave    int               `json:"wave"`
+	Ticks   int               `json:"ticks"`
+	Score   int               `json:"score"`
+	Cleared bool              `json:"cleared"`
+	Inputs  []telemetryInputs `json:"inputs"` // One per player.
+}
+
+type telemetryDeath struct {
+	telemetryRecord
+	Wave   int     `json:"wave"`
+	Player int     `json:"player"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	EnemyX float64 `json:"enemyX"`
+	EnemyY float64 `json:"enemyY"`
+	Down   bool    `json:"down"` // A co-op player waiting for a revive.
+}
+
+type telemetryRunEnd struct {
+	telemetryRecord
+	Reason string `json:"reason"` // "gameOver" or "quit".
+	Wave   int    `json:"wave"`
+	Score  int    `json:"score"`
+}
+
+// telemetryRun is the state of the run being logged.
+type telemetryRun struct {
+	run       int
+	ticks     int
+	wave      int // The wave being logged, or the survival level.
+	waveStart int
+	cleared   bool // The wave was won, rather than jumped past in debug mode.
+	inputs    [2]telemetryInputs
+}
+
+// newSessionID names one launch of the game so runs from the same sitting
+// can be grouped.
+func newSessionID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// buildVersion names the build from the module version or the commit it was
+// built from.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value[:min(12, len(setting.Value))]
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return info.Main.Version
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+func (g *Game) telemetryHeader(kind string) telemetryRecord {
+	return telemetryRecord{Type: kind, Session: g.sessionID, Run: g.telemetry.run, Tick: g.telemetry.ticks}
+}
+
+func (g *Game) writeTelemetry(record any) {
+	data, err := json.Marshal(record)
+	if err == nil {
+		err = g.telemetrySink.Write(data)
+	}
+	if err != nil {
+		log.Printf("write telemetry: %v", err)
+	}
+}
+
+// startTelemetry begins logging a run when the player opted in.
+func (g *Game) startTelemetry() {
+	g.telemetry = nil
+	if !g.settings.Telemetry || g.telemetrySink == nil {
+		return
+	}
+	g.telemetryRuns++
+	g.telemetry = &telemetryRun{run: g.telemetryRuns, wave: g.world.Level()}
+	g.writeTelemetry(telemetryRunStart{
+		telemetryRecord: g.telemetryHeader("runStart"),
+		Time:            time.Now().UTC().Format(time.RFC3339),
+		Version:         buildVersion(),
+		Seed:            g.world.Seed,
+		Mode:            g.mode,
+		Difficulty:      g.world.Difficulty,
+		Coop:            g.world.Coop,
+		Online:          g.net != nil,
+		Assisted:        g.assisted,
+	})
+}
+
+func (g *Game) telemetryTick() {
+	if g.telemetry != nil {
+		g.telemetry.ticks++
+	}
+}
+
+// telemetryControls counts the controls the standing players hold this tick.
+func (g *Game) telemetryControls(controls [2]sim.Controls) {
+	if g.telemetry == nil {
+		return
+	}
+	for index := range g.world.PilotCount() {
+		if g.world.Pilots[index].Down {
+			continue
+		}
+		inputs := &g.telemetry.inputs[index]
+		if controls[index].Left {
+			inputs.Left++
+		}
+		if controls[index].Right {
+			inputs.Right++
+		}
+		if controls[index].Fire {
+			inputs.Fire++
+		}
+		if controls[index].Special {
+			inputs.Special++
+		}
+	}
+}
+
+// finishTelemetryWave logs the wave that just ended and starts counting the
+// next one. Survival has a single wave, so its levels are logged as waves.
+func (g *Game) finishTelemetryWave() {
+	run := g.telemetry
+	g.writeTelemetry(telemetryWave{
+		telemetryRecord: g.telemetryHeader("wave"),
+		Wave:            run.wave,
+		Ticks:           run.ticks - run.waveStart,
+		Score:           g.world.Score,
+		Cleared:         run.cleared,
+		Inputs:          run.inputs[:g.world.PilotCount()],
+	})
+	run.wave = g.world.Level()
+	run.waveStart = run.ticks
+	run.cleared = false
+	run.inputs = [2]telemetryInputs{}
+}
+
+// telemetryEvent logs the wave changes, deaths and shots of the current run.
+func (g *Game) telemetryEvent(event sim.Event) {
+	if g.telemetry == nil {
+		return
+	}
+	switch event := event.(type) {
+	case sim.WaveCleared:
+		g.telemetry.cleared = true
+	case sim.SurvivalRamped:
+		g.telemetry.cleared = true
+		g.finishTelemetryWave()
+	case sim.WaveStarted:
+		g.finishTelemetryWave()
+	case sim.ShotFired:
+		g.telemetry.inputs[event.Shooter].Shots++
+	case sim.PlayerDied:
+		p := g.world.Pilots[event.Player].Position
+		g.writeTelemetry(telemetryDeath{
+			telemetryRecord: g.telemetryHeader("death"),
+			Wave:            g.telemetry.wave,
+			Player:          event.Player,
+			X:               p.X,
+			Y:               p.Y,
+			EnemyX:          event.Enemy.X,
+			EnemyY:          event.Enemy.Y,
+			Down:            event.Down,
+		})
+	}
+}
+
+// finishTelemetry logs the end of the run.
+func (g *Game) finishTelemetry(reason string) {
+	if g.telemetry == nil {
+		return
+	}
+	g.finishTelemetryWave()
+	g.writeTelemetry(telemetryRunEnd{
+		telemetryRecord: g.telemetryHeader("runEnd"),
+		Reason:          reason,
+		Wave:            g.world.Wave,
+		Score:           g.world.Score,
+	})
+	g.telemetry = nil
+}
+
+func (g *Game) telemetryMenuItems() []menuItem {
+	exported := ""
+	return []menuItem{
+		g.toggleItem("telemetry.record", &g.settings.Telemetry),
+		{
+			label: "telemetry.export",
+			value: func() string {
+				if exported != "" {
+					return exported
+				}
+				return ">"
+			},
+			adjust: func(delta int) {
+				if delta <= 0 || g.telemetrySink == nil {
+					return
+				}
+				if err := g.telemetrySink.Export(); err != nil {
+					log.Printf("export telemetry: %v", err)
+					exported = g.message("telemetry.exportFailed")
+					return
+				}
+				exported = g.message("telemetry.exported")
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+//go:build !js
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// fileTelemetrySink appends the run log to telemetry.jsonl next to the other
+// save files.
+type fileTelemetrySink struct{}
+
+func newTelemetrySink() telemetrySink {
+	return fileTelemetrySink{}
+}
+
+func telemetryPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "mygame", "telemetry.jsonl"), nil
+}
+
+func (fileTelemetrySink) Write(record []byte) error {
+	path, err := telemetryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(record, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Export copies the log next to where the game was started, like the stats
+// export.
+func (fileTelemetrySink) Export() error {
+	source, err := telemetryPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	path, err := filepath.Abs(telemetryExportName)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	log.Printf("exported telemetry to %s", path)
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
+)
+
+type fakeTelemetrySink struct {
+	records []map[string]any
+}
+
+func (sink *fakeTelemetrySink) Write(record []byte) error {
+	var decoded map[string]any
+	if err := json.Unmarshal(record, &decoded); err != nil {
+		return err
+	}
+	sink.records = append(sink.records, decoded)
+	return nil
+}
+
+func (sink *fakeTelemetrySink) Export() error {
+	return nil
+}
+
+func TestTelemetryLogsRun(t *testing.T) {
+	sink := &fakeTelemetrySink{}
+	g := &Game{telemetrySink: sink, sessionID: "session", world: &sim.World{Config: sim.Config{Seed: 42}, Wave: 1}}
+	g.settings.Telemetry = true
+	g.events.subscribe(g.telemetryEvent)
+	g.startTelemetry()
+	for range 30 {
+		g.telemetryControls([2]sim.Controls{{Left: true, Fire: true}})
+		g.telemetryTick()
+	}
+	g.emit(sim.ShotFired{Shooter: sim.PlayerOne, Count: 1})
+	g.emit(sim.WaveCleared{Wave: 1})
+	g.world.Wave = 2
+	g.emit(sim.WaveStarted{Wave: 2})
+	g.telemetryTick()
+	g.emit(sim.PlayerDied{Player: sim.PlayerOne, Enemy: sim.Point{X: 5, Y: 6}})
+	g.finishTelemetry("gameOver")
+
+	var kinds []string
+	for _, record := range sink.records {
+		kinds = append(kinds, record["type"].(string))
+		if record["session"] != "session" || record["run"] != 1.0 {
+			t.Fatalf("record %v is missing the session or run", record)
+		}
+	}
+	want := []string{"runStart", "wave", "death", "wave", "runEnd"}
+	if len(kinds) != len(want) {
+		t.Fatalf("records = %v, want %v", kinds, want)
+	}
+	for index := range want {
+		if kinds[index] != want[index] {
+			t.Fatalf("records = %v, want %v", kinds, want)
+		}
+	}
+	if sink.records[0]["seed"] != 42.0 {
+		t.Fatalf("run start = %v, want seed 42", sink.records[0])
+	}
+	firstWave := sink.records[1]
+	inputs := firstWave["inputs"].([]any)[0].(map[string]any)
+	if firstWave["wave"] != 1.0 || firstWave["ticks"] != 30.0 || firstWave["cleared"] != true ||
+		inputs["left"] != 30.0 || inputs["fire"] != 30.0 || inputs["shots"] != 1.0 {
+		t.Fatalf("first wave = %v, want 30 ticks of left and fire with one shot", firstWave)
+	}
+	death := sink.records[2]
+	if death["wave"] != 2.0 || death["enemyX"] != 5.0 || death["enemyY"] != 6.0 || death["tick"] != 31.0 {
+		t.Fatalf("death = %v", death)
+	}
+	if lastWave := sink.records[3]; lastWave["wave"] != 2.0 || lastWave["ticks"] != 1.0 || lastWave["cleared"] != false {
+		t.Fatalf("last wave = %v, want wave 2 not cleared after 1 tick", lastWave)
+	}
+	if g.telemetry != nil {
+		t.Fatal("telemetry still running after the run ended")
+	}
+}
+
+func telemetryWaves(sink *fakeTelemetrySink) (waves []float64, cleared []bool) {
+	for _, record := range sink.records {
+		if record["type"] == "wave" {
+			waves = append(waves, record["wave"].(float64))
+			cleared = append(cleared, record["cleared"].(bool))
+		}
+	}
+	return waves, cleared
+}
+
+func TestTelemetryLogsJumpsAsNotCleared(t *testing.T) {
+	sink := &fakeTelemetrySink{}
+	g := &Game{telemetrySink: sink, world: &sim.World{Wave: 2}}
+	g.settings.Telemetry = true
+	g.events.subscribe(g.telemetryEvent)
+	g.startTelemetry()
+	g.world.Wave = sim.BossWaveCycle
+	g.emit(sim.WaveStarted{Wave: sim.BossWaveCycle})
+	g.finishTelemetry("quit")
+	waves, cleared := telemetryWaves(sink)
+	if !reflect.DeepEqual(waves, []float64{2, sim.BossWaveCycle}) || !reflect.DeepEqual(cleared, []bool{false, false}) {
+		t.Fatalf("waves = %v cleared = %v, want the jumped wave not cleared", waves, cleared)
+	}
+}
+
+func TestTelemetryLogsSurvivalLevels(t *testing.T) {
+	sink := &fakeTelemetrySink{}
+	g := &Game{telemetrySink: sink, world: &sim.World{Config: sim.Config{Mode: sim.ModeSurvival}, Wave: 1}}
+	g.settings.Telemetry = true
+	g.events.subscribe(g.telemetryEvent)
+	g.startTelemetry()
+	for level := 2; level <= 3; level++ {
+		g.world.SurvivalLevel++
+		g.emit(sim.SurvivalRamped{Level: level})
+	}
+	g.finishTelemetry("gameOver")
+	waves, cleared := telemetryWaves(sink)
+	if !reflect.DeepEqual(waves, []float64{1, 2, 3}) || !reflect.DeepEqual(cleared, []bool{true, true, false}) {
+		t.Fatalf("waves = %v cleared = %v, want one record per survival level", waves, cleared)
+	}
+}
+
+func TestTelemetryLogsTheDifficultyPlayed(t *testing.T) {
+	sink := &fakeTelemetrySink{}
+	g := &Game{telemetrySink: sink, world: &sim.World{Config: sim.Config{Difficulty: sim.DifficultyNormal}, Wave: 1}}
+	g.settings.Telemetry = true
+	g.settings.Difficulty = sim.DifficultyLunatic
+	g.startTelemetry()
+	if difficulty := sink.records[0]["difficulty"]; difficulty != string(sim.DifficultyNormal) {
+		t.Fatalf("difficulty = %v, want the world's %q rather than the setting", difficulty, sim.DifficultyNormal)
+	}
+}
+
+func TestTelemetryIsOptIn(t *testing.T) {
+	sink := &fakeTelemetrySink{}
+	g := &Game{telemetrySink: sink}
+	g.startTelemetry()
+	g.telemetryTick()
+	g.finishTelemetry("quit")
+	if len(sink.records) != 0 {
+		t.Fatalf("records = %v without opting in", sink.records)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build js
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"syscall/js"
+)
+
+// maxTelemetryRecords keeps the in-memory log from growing without bound in a
+// long browser session.
+const maxTelemetryRecords = 10000
+
+// consoleTelemetrySink prints each record to the browser console and keeps
+// the records of this session for downloading.
+type consoleTelemetrySink struct {
+	records [][]byte
+}
+
+func newTelemetrySink() telemetrySink {
+	return &consoleTelemetrySink{}
+}
+
+func (sink *consoleTelemetrySink) Write(record []byte) error {
+	if len(sink.records) == maxTelemetryRecords {
+		sink.records = sink.records[1:]
+	}
+	sink.records = append(sink.records, record)
+	js.Global().Get("console").Call("log", string(record))
+	return nil
+}
+
+// Export downloads the records of this session as a JSONL file.
+func (sink *consoleTelemetrySink) Export() error {
+	if len(sink.records) == 0 {
+		return errors.New("no telemetry recorded yet")
+	}
+	var data bytes.Buffer
+	for _, record := range sink.records {
+		data.Write(record)
+		data.WriteByte('\n')
+	}
+	return downloadFile(telemetryExportName, "application/x-ndjson", data.Bytes())
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"slices"
+
+	"github.com/Kenshu-Miura/mygame/internal/sim"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
@@ -59,32 +62,45 @@ func (gesture *touchGesture) finish(x, y int) (int, touchAction) {
 	return deltaX, touchActionNone
 }
 
-func (g *Game) handleTouchInput() {
+// touchControls turns the gesture in progress into player one's controls:
+// dragging moves the player, a tap shoots and an upward swipe uses KIEE.
+func (g *Game) touchControls() sim.Controls {
 	if !g.touch.active {
-		ids := inpututil.AppendJustPressedTouchIDs(nil)
+		ids := g.gameplayTouchIDs()
 		if len(ids) == 0 {
-			return
+			return sim.Controls{}
 		}
-		x, y := ebiten.TouchPosition(ids[0])
-		g.touch.begin(ids[0], x, y)
-		return
+		position := g.touchPosition(ids[0])
+		g.touch.begin(ids[0], position.X, position.Y)
+		return sim.Controls{}
 	}
 
 	if inpututil.IsTouchJustReleased(g.touch.id) {
-		x, y := inpututil.TouchPositionInPreviousTick(g.touch.id)
-		deltaX, action := g.touch.finish(x, y)
-		g.movePlayerHorizontally(float64(deltaX))
-		g.touchShot = action == touchActionShot
-		g.touchSpecial = action == touchActionSpecial
-		return
+		position := g.previousTouchPosition(g.touch.id)
+		deltaX, action := g.touch.finish(position.X, position.Y)
+		return sim.Controls{
+			Drag:    float64(deltaX),
+			Tap:     action == touchActionShot,
+			Special: action == touchActionSpecial,
+		}
 	}
 
-	x, y := ebiten.TouchPosition(g.touch.id)
-	g.movePlayerHorizontally(float64(g.touch.track(x, y)))
+	position := g.touchPosition(g.touch.id)
+	return sim.Controls{Drag: float64(g.touch.track(position.X, position.Y))}
+}
+
+func (g *Game) touchJustPressed() bool {
+	return len(g.gameplayTouchIDs()) > 0
 }
 
-func touchJustPressed() bool {
-	return len(inpututil.AppendJustPressedTouchIDs(nil)) > 0
+// gameplayTouchIDs returns the touches that started this tick, leaving out
+// taps on the mute button.
+func (g *Game) gameplayTouchIDs() []ebiten.TouchID {
+	ids := inpututil.AppendJustPressedTouchIDs(nil)
+	button := muteButtonRect()
+	return slices.DeleteFunc(ids, func(id ebiten.TouchID) bool {
+		return g.touchPosition(id).In(button)
+	})
 }
 
 func intAbs(value int) int {
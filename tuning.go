@@ -0,0 +1,10 @@
+package main
+
+import "github.com/Kenshu-Miura/mygame/internal/sim"
+
+// tuningPath is read in debug mode and reloaded whenever it changes.
+const tuningPath = "tuning.json"
+
+// tuning is only written from Update, so the simulation never sees a
+// half-applied file.
+var tuning = sim.DefaultTuning()
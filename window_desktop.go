@@ -0,0 +1,47 @@
+//go:build !js
+
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const windowSaveDelay = 30 // Ticks the window must stay put before its size and position are saved.
+
+// applyWindowSettings restores the window before the game starts.
+func (g *Game) applyWindowSettings() {
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowSizeLimits(screenWidth/2, screenHeight/2, -1, -1)
+	window := g.settings.Display.Window
+	if window.Width > 0 && window.Height > 0 {
+		ebiten.SetWindowSize(window.Width, window.Height)
+		ebiten.SetWindowPosition(window.X, window.Y)
+	} else {
+		ebiten.SetWindowSize(screenWidth*windowScale, screenHeight*windowScale)
+	}
+	ebiten.SetFullscreen(g.settings.Display.Fullscreen)
+}
+
+// trackWindow remembers the windowed size and position, saving them once the
+// player stops moving or resizing the window.
+func (g *Game) trackWindow() {
+	if fullscreen := ebiten.IsFullscreen(); fullscreen != g.settings.Display.Fullscreen {
+		g.settings.Display.Fullscreen = fullscreen
+		g.saveSettings()
+	}
+	if ebiten.IsFullscreen() || ebiten.IsWindowMaximized() || ebiten.IsWindowMinimized() {
+		return
+	}
+	x, y := ebiten.WindowPosition()
+	width, height := ebiten.WindowSize()
+	current := windowGeometry{X: x, Y: y, Width: width, Height: height}
+	if current != g.settings.Display.Window {
+		g.settings.Display.Window = current
+		g.windowSaveTicks = windowSaveDelay
+		return
+	}
+	if g.windowSaveTicks > 0 {
+		g.windowSaveTicks--
+		if g.windowSaveTicks == 0 {
+			g.saveSettings()
+		}
+	}
+}
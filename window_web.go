@@ -0,0 +1,9 @@
+//go:build js
+
+package main
+
+// applyWindowSettings does nothing in the browser, where the page sizes the
+// canvas and fullscreen needs a key press or tap first.
+func (g *Game) applyWindowSettings() {}
+
+func (g *Game) trackWindow() {}